@@ -0,0 +1,66 @@
+package chatstore
+
+import (
+	"context"
+	"time"
+)
+
+// ImportLegacy copies the rooms and sync state that watui kept next to the
+// whatsmeow session, in the database at path, before chats moved to a store
+// per account. The old tables are dropped afterwards, so only the first
+// account opened takes them over.
+func (s *Store) ImportLegacy(ctx context.Context, path string) error {
+	if !s.acquire() {
+		return nil
+	}
+	defer s.release()
+
+	// ATTACH holds for one connection only.
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// whatsmeow has the session database open as well.
+	if _, err := conn.ExecContext(ctx, `PRAGMA busy_timeout = 5000`); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS legacy`, path); err != nil {
+		return err
+	}
+	defer conn.ExecContext(context.Background(), `DETACH DATABASE legacy`)
+
+	var tables int
+	err = conn.QueryRowContext(ctx, `
+SELECT COUNT(*) FROM legacy.sqlite_master
+WHERE type = 'table' AND name IN ('chat_rooms', 'sync_state')`).Scan(&tables)
+	if err != nil || tables < 2 {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+INSERT OR IGNORE INTO chat_rooms (jid, title, last_message, last_ts, unread_count, updated_at)
+SELECT jid, title, last_message, last_ts, unread_count, COALESCE(updated_at, ?) FROM legacy.chat_rooms`, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range []string{`
+INSERT OR IGNORE INTO sync_state (id, progress, chunk_order, sync_type, in_progress, updated_at)
+SELECT id, progress, chunk_order, sync_type, in_progress, updated_at FROM legacy.sync_state`,
+		`DROP TABLE legacy.chat_rooms`,
+		`DROP TABLE legacy.sync_state`,
+	} {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,282 @@
+package chatstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// TypeRevoked marks a message its sender deleted for everyone. Its body and
+// raw content are gone.
+const TypeRevoked = "revoked"
+
+type Message struct {
+	ID        string
+	ChatJID   string
+	SenderJID string
+	Timestamp time.Time
+	FromMe    bool
+	Type      string
+	Body      string
+	Raw       []byte
+	Status    MessageStatus
+	// EditedAt is when the body was last edited, zero if it never was.
+	EditedAt time.Time
+}
+
+// MessageCursor points at a message in a chat's timeline. The zero value
+// points past the newest message.
+type MessageCursor struct {
+	Timestamp time.Time
+	ID        string
+}
+
+func (c MessageCursor) IsZero() bool {
+	return c.Timestamp.IsZero() && c.ID == ""
+}
+
+func (msg Message) Cursor() MessageCursor {
+	return MessageCursor{Timestamp: msg.Timestamp, ID: msg.ID}
+}
+
+type preparer interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+func (s *Store) SaveMessages(ctx context.Context, messages []Message) error {
+	if len(messages) == 0 || !s.acquire() {
+		return nil
+	}
+	defer s.release()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := insertMessages(ctx, tx, messages); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertMessages(ctx context.Context, db preparer, messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	stmt, err := db.PrepareContext(ctx, `
+INSERT INTO messages (chat_jid, id, sender_jid, ts, from_me, type, body, raw, status)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(chat_jid, id) DO UPDATE SET
+	sender_jid=excluded.sender_jid,
+	ts=excluded.ts,
+	from_me=excluded.from_me,
+	type=CASE WHEN messages.type = 'revoked' THEN messages.type ELSE excluded.type END,
+	body=CASE WHEN messages.type = 'revoked' OR messages.edited_at > 0 THEN messages.body ELSE excluded.body END,
+	raw=CASE WHEN messages.type = 'revoked' THEN NULL ELSE excluded.raw END,
+	status=MAX(messages.status, excluded.status)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, msg := range messages {
+		if msg.ID == "" || msg.ChatJID == "" {
+			continue
+		}
+
+		_, err = stmt.ExecContext(ctx,
+			msg.ChatJID,
+			msg.ID,
+			msg.SenderJID,
+			msg.Timestamp.Unix(),
+			boolToInt(msg.FromMe),
+			msg.Type,
+			msg.Body,
+			msg.Raw,
+			msg.Status,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MessagesBefore returns up to limit messages of chatJID older than cursor,
+// ordered oldest first. A zero cursor starts from the newest message.
+func (s *Store) MessagesBefore(ctx context.Context, chatJID string, cursor MessageCursor, limit int) ([]Message, error) {
+	if !s.acquire() {
+		return nil, nil
+	}
+	defer s.release()
+	if limit <= 0 {
+		limit = 50
+	}
+
+	var (
+		rows *sql.Rows
+		err  error
+	)
+	if cursor.IsZero() {
+		rows, err = s.db.QueryContext(ctx, `
+SELECT chat_jid, id, sender_jid, ts, from_me, type, body, raw, status, edited_at FROM messages
+WHERE chat_jid = ?
+ORDER BY ts DESC, id DESC
+LIMIT ?`, chatJID, limit)
+	} else {
+		ts := cursor.Timestamp.Unix()
+		rows, err = s.db.QueryContext(ctx, `
+SELECT chat_jid, id, sender_jid, ts, from_me, type, body, raw, status, edited_at FROM messages
+WHERE chat_jid = ? AND (ts < ? OR (ts = ? AND id < ?))
+ORDER BY ts DESC, id DESC
+LIMIT ?`, chatJID, ts, ts, cursor.ID, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+
+	return messages, nil
+}
+
+// MessagesAfter returns up to limit messages of chatJID newer than cursor,
+// ordered oldest first.
+func (s *Store) MessagesAfter(ctx context.Context, chatJID string, cursor MessageCursor, limit int) ([]Message, error) {
+	if !s.acquire() {
+		return nil, nil
+	}
+	defer s.release()
+	if limit <= 0 {
+		limit = 50
+	}
+
+	ts := cursor.Timestamp.Unix()
+	rows, err := s.db.QueryContext(ctx, `
+SELECT chat_jid, id, sender_jid, ts, from_me, type, body, raw, status, edited_at FROM messages
+WHERE chat_jid = ? AND (ts > ? OR (ts = ? AND id > ?))
+ORDER BY ts ASC, id ASC
+LIMIT ?`, chatJID, ts, ts, cursor.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanMessages(rows)
+}
+
+// Message returns one stored message, or nil when it is not in the store.
+func (s *Store) Message(ctx context.Context, chatJID, id string) (*Message, error) {
+	if !s.acquire() {
+		return nil, nil
+	}
+	defer s.release()
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT chat_jid, id, sender_jid, ts, from_me, type, body, raw, status, edited_at FROM messages
+WHERE chat_jid = ? AND id = ?`, chatJID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil || len(messages) == 0 {
+		return nil, err
+	}
+	return &messages[0], nil
+}
+
+func scanMessages(rows *sql.Rows) ([]Message, error) {
+	var messages []Message
+	for rows.Next() {
+		var (
+			chatJID, id, sender, msgType, body sql.NullString
+			ts, fromMe, status, editedAt       sql.NullInt64
+			raw                                []byte
+		)
+		if err := rows.Scan(&chatJID, &id, &sender, &ts, &fromMe, &msgType, &body, &raw, &status, &editedAt); err != nil {
+			return nil, err
+		}
+
+		var timestamp, edited time.Time
+		if ts.Valid {
+			timestamp = time.Unix(ts.Int64, 0)
+		}
+		if editedAt.Int64 > 0 {
+			edited = time.Unix(editedAt.Int64, 0)
+		}
+
+		messages = append(messages, Message{
+			ID:        id.String,
+			ChatJID:   chatJID.String,
+			SenderJID: sender.String,
+			Timestamp: timestamp,
+			FromMe:    fromMe.Int64 != 0,
+			Type:      msgType.String,
+			Body:      body.String,
+			Raw:       raw,
+			Status:    MessageStatus(status.Int64),
+			EditedAt:  edited,
+		})
+	}
+
+	return messages, rows.Err()
+}
+
+// EditMessage replaces the body of a message. An edit older than the one
+// already applied, or of a revoked message, is ignored.
+func (s *Store) EditMessage(ctx context.Context, chatJID, id, body string, editedAt time.Time) error {
+	if !s.acquire() {
+		return nil
+	}
+	defer s.release()
+
+	_, err := s.db.ExecContext(ctx, `
+UPDATE messages SET body = ?, edited_at = ?
+WHERE chat_jid = ? AND id = ? AND type != 'revoked' AND edited_at <= ?`,
+		body, editedAt.Unix(), chatJID, id, editedAt.Unix())
+	return err
+}
+
+// RevokeMessage drops the content of a message deleted for everyone, along
+// with the reactions to it.
+func (s *Store) RevokeMessage(ctx context.Context, chatJID, id string) error {
+	if !s.acquire() {
+		return nil
+	}
+	defer s.release()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+UPDATE messages SET type = ?, body = '', raw = NULL
+WHERE chat_jid = ? AND id = ?`, TypeRevoked, chatJID, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+DELETE FROM message_reactions WHERE chat_jid = ? AND message_id = ?`, chatJID, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,142 @@
+package chatstore
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func messageIDs(messages []Message) []string {
+	ids := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
+
+func TestMessagePaging(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	const chat = "a@s.whatsapp.net"
+	base := time.Unix(1700000000, 0)
+	// b and c share a timestamp, so the cursor has to go by id as well.
+	err := s.SaveMessages(ctx, []Message{
+		{ChatJID: chat, ID: "a", Timestamp: base, Body: "1"},
+		{ChatJID: chat, ID: "b", Timestamp: base.Add(time.Second), Body: "2"},
+		{ChatJID: chat, ID: "c", Timestamp: base.Add(time.Second), Body: "3"},
+		{ChatJID: chat, ID: "d", Timestamp: base.Add(2 * time.Second), Body: "4"},
+		{ChatJID: chat, ID: "e", Timestamp: base.Add(3 * time.Second), Body: "5"},
+		{ChatJID: "other@s.whatsapp.net", ID: "x", Timestamp: base, Body: "elsewhere"},
+	})
+	if err != nil {
+		t.Fatalf("SaveMessages: %v", err)
+	}
+
+	var pages [][]string
+	cursor := MessageCursor{}
+	for {
+		page, err := s.MessagesBefore(ctx, chat, cursor, 2)
+		if err != nil {
+			t.Fatalf("MessagesBefore: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		pages = append(pages, messageIDs(page))
+		cursor = page[0].Cursor()
+	}
+	want := [][]string{{"d", "e"}, {"b", "c"}, {"a"}}
+	if !slices.EqualFunc(pages, want, slices.Equal) {
+		t.Errorf("pages before = %v, want %v", pages, want)
+	}
+
+	pages = nil
+	cursor = MessageCursor{Timestamp: base.Add(-time.Second)}
+	for {
+		page, err := s.MessagesAfter(ctx, chat, cursor, 2)
+		if err != nil {
+			t.Fatalf("MessagesAfter: %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		pages = append(pages, messageIDs(page))
+		cursor = page[len(page)-1].Cursor()
+	}
+	want = [][]string{{"a", "b"}, {"c", "d"}, {"e"}}
+	if !slices.EqualFunc(pages, want, slices.Equal) {
+		t.Errorf("pages after = %v, want %v", pages, want)
+	}
+}
+
+func TestEnsureSchemaAddsEditedAt(t *testing.T) {
+	s := newStoreFrom(t, firstMessagesTable+`
+INSERT INTO messages VALUES ('a@s.whatsapp.net', 'm1', '', 100, 0, 'text', 'hi', NULL);`)
+	ctx := context.Background()
+
+	msg, err := s.Message(ctx, "a@s.whatsapp.net", "m1")
+	if err != nil {
+		t.Fatalf("Message: %v", err)
+	}
+	if msg == nil || msg.Body != "hi" || !msg.EditedAt.IsZero() {
+		t.Fatalf("message = %+v", msg)
+	}
+
+	if err := s.EditMessage(ctx, "a@s.whatsapp.net", "m1", "hello", time.Unix(200, 0)); err != nil {
+		t.Fatalf("EditMessage: %v", err)
+	}
+	if msg, _ := s.Message(ctx, "a@s.whatsapp.net", "m1"); msg == nil || msg.Body != "hello" || msg.EditedAt.Unix() != 200 {
+		t.Errorf("message = %+v", msg)
+	}
+}
+
+func TestEditAndRevokeSurviveResync(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	const chat = "a@s.whatsapp.net"
+	original := []Message{
+		{ChatJID: chat, ID: "m1", Timestamp: time.Unix(100, 0), Type: "text", Body: "first"},
+		{ChatJID: chat, ID: "m2", Timestamp: time.Unix(101, 0), Type: "text", Body: "second", Raw: []byte{1}},
+	}
+	if err := s.SaveMessages(ctx, original); err != nil {
+		t.Fatalf("SaveMessages: %v", err)
+	}
+
+	if err := s.EditMessage(ctx, chat, "m1", "edited", time.Unix(300, 0)); err != nil {
+		t.Fatalf("EditMessage: %v", err)
+	}
+	// An edit older than the applied one arrives late.
+	if err := s.EditMessage(ctx, chat, "m1", "stale", time.Unix(200, 0)); err != nil {
+		t.Fatalf("EditMessage: %v", err)
+	}
+	if err := s.RevokeMessage(ctx, chat, "m2"); err != nil {
+		t.Fatalf("RevokeMessage: %v", err)
+	}
+	if err := s.EditMessage(ctx, chat, "m2", "after revoke", time.Unix(400, 0)); err != nil {
+		t.Fatalf("EditMessage: %v", err)
+	}
+
+	// History sync brings the original messages again.
+	if err := s.SaveMessages(ctx, original); err != nil {
+		t.Fatalf("SaveMessages: %v", err)
+	}
+
+	msg, err := s.Message(ctx, chat, "m1")
+	if err != nil {
+		t.Fatalf("Message: %v", err)
+	}
+	if msg.Body != "edited" {
+		t.Errorf("edited body = %q, want %q", msg.Body, "edited")
+	}
+
+	msg, err = s.Message(ctx, chat, "m2")
+	if err != nil {
+		t.Fatalf("Message: %v", err)
+	}
+	if msg.Type != TypeRevoked || msg.Body != "" || msg.Raw != nil {
+		t.Errorf("revoked message = %+v", msg)
+	}
+}
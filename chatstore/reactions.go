@@ -0,0 +1,85 @@
+package chatstore
+
+import (
+	"context"
+	"time"
+)
+
+// Reaction is the emoji a participant put on a message. An empty Emoji
+// takes the participant's reaction back; it is kept with its time, so an
+// older reaction arriving later does not come back.
+type Reaction struct {
+	MessageID string
+	SenderJID string
+	Emoji     string
+	Timestamp time.Time
+}
+
+// SaveReactions records reactions in a chat. Each participant keeps one
+// reaction per message, and one older than the stored one is ignored.
+func (s *Store) SaveReactions(ctx context.Context, chatJID string, reactions []Reaction) error {
+	if len(reactions) == 0 || !s.acquire() {
+		return nil
+	}
+	defer s.release()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	upsert, err := tx.PrepareContext(ctx, `
+INSERT INTO message_reactions (chat_jid, message_id, sender_jid, emoji, ts)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT(chat_jid, message_id, sender_jid) DO UPDATE SET
+	emoji=excluded.emoji,
+	ts=excluded.ts
+WHERE excluded.ts >= message_reactions.ts`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer upsert.Close()
+
+	for _, r := range reactions {
+		if _, err := upsert.ExecContext(ctx, chatJID, r.MessageID, r.SenderJID, r.Emoji, r.Timestamp.UnixMilli()); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Reactions returns every reaction in a chat, removals included, oldest
+// first.
+func (s *Store) Reactions(ctx context.Context, chatJID string) ([]Reaction, error) {
+	if !s.acquire() {
+		return nil, nil
+	}
+	defer s.release()
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT message_id, sender_jid, emoji, ts FROM message_reactions
+WHERE chat_jid = ?
+ORDER BY ts`, chatJID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reactions []Reaction
+	for rows.Next() {
+		var (
+			r  Reaction
+			ts int64
+		)
+		if err := rows.Scan(&r.MessageID, &r.SenderJID, &r.Emoji, &ts); err != nil {
+			return nil, err
+		}
+		r.Timestamp = time.UnixMilli(ts)
+		reactions = append(reactions, r)
+	}
+
+	return reactions, rows.Err()
+}
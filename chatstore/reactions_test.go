@@ -0,0 +1,44 @@
+package chatstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSaveReactionsKeepsNewest(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	const chat = "a@s.whatsapp.net"
+	base := time.UnixMilli(1700000000123)
+	for _, r := range []Reaction{
+		{MessageID: "m1", SenderJID: "b@s.whatsapp.net", Emoji: "👍", Timestamp: base},
+		// Taken back, then an add from before the removal arrives late.
+		{MessageID: "m1", SenderJID: "b@s.whatsapp.net", Emoji: "", Timestamp: base.Add(time.Millisecond)},
+		{MessageID: "m1", SenderJID: "b@s.whatsapp.net", Emoji: "❤️", Timestamp: base.Add(-time.Second)},
+		{MessageID: "m1", SenderJID: "c@s.whatsapp.net", Emoji: "😂", Timestamp: base.Add(time.Second)},
+	} {
+		if err := s.SaveReactions(ctx, chat, []Reaction{r}); err != nil {
+			t.Fatalf("SaveReactions: %v", err)
+		}
+	}
+
+	reactions, err := s.Reactions(ctx, chat)
+	if err != nil {
+		t.Fatalf("Reactions: %v", err)
+	}
+	want := []Reaction{
+		{MessageID: "m1", SenderJID: "b@s.whatsapp.net", Emoji: "", Timestamp: base.Add(time.Millisecond)},
+		{MessageID: "m1", SenderJID: "c@s.whatsapp.net", Emoji: "😂", Timestamp: base.Add(time.Second)},
+	}
+	if len(reactions) != len(want) {
+		t.Fatalf("reactions = %+v, want %+v", reactions, want)
+	}
+	for i := range want {
+		got := reactions[i]
+		if got.MessageID != want[i].MessageID || got.SenderJID != want[i].SenderJID || got.Emoji != want[i].Emoji || !got.Timestamp.Equal(want[i].Timestamp) {
+			t.Errorf("reactions[%d] = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package chatstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// MessageStatus is the delivery state of an outgoing message. Later states
+// never go back to earlier ones.
+type MessageStatus int
+
+const (
+	StatusUnknown MessageStatus = iota
+	StatusSent
+	StatusDelivered
+	StatusRead
+	StatusPlayed
+)
+
+// Receipt records when a participant received, read and played a message.
+// Zero times mean the receipt has not arrived.
+type Receipt struct {
+	Participant string
+	DeliveredAt time.Time
+	ReadAt      time.Time
+	PlayedAt    time.Time
+}
+
+// UpdateMessageStatus raises the status of our own messages.
+func (s *Store) UpdateMessageStatus(ctx context.Context, chatJID string, ids []string, status MessageStatus) error {
+	if len(ids) == 0 || !s.acquire() {
+		return nil
+	}
+	defer s.release()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+UPDATE messages SET status = ?
+WHERE chat_jid = ? AND id = ? AND from_me = 1 AND status < ?`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range ids {
+		if _, err := stmt.ExecContext(ctx, status, chatJID, id, status); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SaveReceipt records a participant's receipt for messages. Reading implies
+// delivery, and the first time of every state is kept.
+func (s *Store) SaveReceipt(ctx context.Context, chatJID string, ids []string, participant string, status MessageStatus, at time.Time) error {
+	if len(ids) == 0 || participant == "" || status < StatusDelivered || !s.acquire() {
+		return nil
+	}
+	defer s.release()
+
+	ts := at.Unix()
+	var readAt, playedAt sql.NullInt64
+	if status >= StatusRead {
+		readAt = sql.NullInt64{Int64: ts, Valid: true}
+	}
+	if status >= StatusPlayed {
+		playedAt = sql.NullInt64{Int64: ts, Valid: true}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+INSERT INTO message_receipts (chat_jid, message_id, participant, delivered_at, read_at, played_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(chat_jid, message_id, participant) DO UPDATE SET
+	delivered_at=COALESCE(message_receipts.delivered_at, excluded.delivered_at),
+	read_at=COALESCE(message_receipts.read_at, excluded.read_at),
+	played_at=COALESCE(message_receipts.played_at, excluded.played_at)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, id := range ids {
+		if _, err := stmt.ExecContext(ctx, chatJID, id, participant, ts, readAt, playedAt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SharedStatus returns the status every one of participants has reached for
+// each of ids, as a group shows it. Messages no one has received are left
+// out.
+func (s *Store) SharedStatus(ctx context.Context, chatJID string, ids []string, participants int) (map[string]MessageStatus, error) {
+	if len(ids) == 0 || participants <= 0 || !s.acquire() {
+		return nil, nil
+	}
+	defer s.release()
+
+	stmt, err := s.db.PrepareContext(ctx, `
+SELECT COUNT(delivered_at), COUNT(read_at), COUNT(played_at) FROM message_receipts
+WHERE chat_jid = ? AND message_id = ?`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	statuses := make(map[string]MessageStatus, len(ids))
+	for _, id := range ids {
+		var delivered, read, played int
+		if err := stmt.QueryRowContext(ctx, chatJID, id).Scan(&delivered, &read, &played); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case played >= participants:
+			statuses[id] = StatusPlayed
+		case read >= participants:
+			statuses[id] = StatusRead
+		case delivered >= participants:
+			statuses[id] = StatusDelivered
+		}
+	}
+
+	return statuses, nil
+}
+
+// Receipts returns the participant receipts of a message, most advanced
+// first.
+func (s *Store) Receipts(ctx context.Context, chatJID, messageID string) ([]Receipt, error) {
+	if !s.acquire() {
+		return nil, nil
+	}
+	defer s.release()
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT participant, delivered_at, read_at, played_at FROM message_receipts
+WHERE chat_jid = ? AND message_id = ?
+ORDER BY played_at IS NULL, read_at IS NULL, COALESCE(played_at, read_at, delivered_at)`, chatJID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var receipts []Receipt
+	for rows.Next() {
+		var (
+			receipt                 Receipt
+			delivered, read, played sql.NullInt64
+		)
+		if err := rows.Scan(&receipt.Participant, &delivered, &read, &played); err != nil {
+			return nil, err
+		}
+
+		receipt.DeliveredAt = unixTime(delivered)
+		receipt.ReadAt = unixTime(read)
+		receipt.PlayedAt = unixTime(played)
+		receipts = append(receipts, receipt)
+	}
+
+	return receipts, rows.Err()
+}
+
+func unixTime(ts sql.NullInt64) time.Time {
+	if !ts.Valid {
+		return time.Time{}
+	}
+	return time.Unix(ts.Int64, 0)
+}
@@ -0,0 +1,117 @@
+package chatstore
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEnsureSchemaAddsMessageStatus(t *testing.T) {
+	s := newStoreFrom(t, firstMessagesTable+`
+INSERT INTO messages VALUES ('a@s.whatsapp.net', 'm1', '', 100, 1, 'text', 'hi', NULL);`)
+	ctx := context.Background()
+
+	msg, err := s.Message(ctx, "a@s.whatsapp.net", "m1")
+	if err != nil {
+		t.Fatalf("Message: %v", err)
+	}
+	if msg == nil || msg.Status != StatusUnknown {
+		t.Fatalf("message = %+v", msg)
+	}
+
+	if err := s.UpdateMessageStatus(ctx, "a@s.whatsapp.net", []string{"m1"}, StatusDelivered); err != nil {
+		t.Fatalf("UpdateMessageStatus: %v", err)
+	}
+	if msg, _ := s.Message(ctx, "a@s.whatsapp.net", "m1"); msg == nil || msg.Status != StatusDelivered {
+		t.Errorf("message = %+v", msg)
+	}
+}
+
+func TestEnsureColumnAddsOnce(t *testing.T) {
+	s := newTestStore(t)
+
+	for range 2 {
+		if err := s.ensureColumn("chat_rooms", "extra", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			t.Fatalf("ensureColumn: %v", err)
+		}
+	}
+
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('chat_rooms') WHERE name = 'extra'`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("extra columns = %d, want 1", n)
+	}
+}
+
+func TestSaveMessagesKeepsHighestStatus(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	const chat = "a@s.whatsapp.net"
+	msg := Message{ChatJID: chat, ID: "m1", Timestamp: time.Unix(1700000000, 0), FromMe: true, Body: "hi"}
+
+	for _, tc := range []struct {
+		save MessageStatus
+		want MessageStatus
+	}{
+		{StatusRead, StatusRead},
+		{StatusSent, StatusRead},
+		{StatusUnknown, StatusRead},
+		{StatusPlayed, StatusPlayed},
+	} {
+		msg.Status = tc.save
+		if err := s.SaveMessages(ctx, []Message{msg}); err != nil {
+			t.Fatalf("SaveMessages: %v", err)
+		}
+
+		stored, err := s.Message(ctx, chat, "m1")
+		if err != nil {
+			t.Fatalf("Message: %v", err)
+		}
+		if stored.Status != tc.want {
+			t.Errorf("after saving %d: status = %d, want %d", tc.save, stored.Status, tc.want)
+		}
+	}
+}
+
+func TestSharedStatus(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	const group = "g@g.us"
+	ids := []string{"m1", "m2"}
+	at := time.Unix(1700000000, 0)
+	save := func(participant string, ids []string, status MessageStatus) {
+		t.Helper()
+		if err := s.SaveReceipt(ctx, group, ids, participant, status, at); err != nil {
+			t.Fatalf("SaveReceipt: %v", err)
+		}
+	}
+	check := func(want map[string]MessageStatus) {
+		t.Helper()
+		got, err := s.SharedStatus(ctx, group, ids, 2)
+		if err != nil {
+			t.Fatalf("SharedStatus: %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("SharedStatus = %v, want %v", got, want)
+		}
+		for id, status := range want {
+			if got[id] != status {
+				t.Errorf("SharedStatus = %v, want %v", got, want)
+			}
+		}
+	}
+
+	save("p1", ids, StatusRead)
+	check(map[string]MessageStatus{})
+
+	save("p2", []string{"m1"}, StatusDelivered)
+	check(map[string]MessageStatus{"m1": StatusDelivered})
+
+	save("p2", []string{"m1"}, StatusRead)
+	save("p2", []string{"m2"}, StatusPlayed)
+	check(map[string]MessageStatus{"m1": StatusRead, "m2": StatusRead})
+}
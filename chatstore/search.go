@@ -0,0 +1,211 @@
+package chatstore
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Snippets returned by Search wrap matched text in these markers.
+const (
+	HighlightStart = "\x02"
+	HighlightEnd   = "\x03"
+)
+
+const snippetContext = 32
+
+type SearchResult struct {
+	Message Message
+	Snippet string
+}
+
+// ensureSearchIndex creates the FTS5 index over message bodies. SQLite
+// builds without FTS5 fall back to LIKE queries in Search, and drop the
+// triggers an FTS5 build left behind, since every write to messages would
+// fail on them. The index is rebuilt once FTS5 is back and finds them
+// missing.
+func (s *Store) ensureSearchIndex() error {
+	var available bool
+	if err := s.db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&available); err != nil {
+		return err
+	}
+	if !available {
+		_, err := s.db.Exec(`
+DROP TRIGGER IF EXISTS messages_fts_ai;
+DROP TRIGGER IF EXISTS messages_fts_ad;
+DROP TRIGGER IF EXISTS messages_fts_au;`)
+		return err
+	}
+
+	var tables, triggers int
+	err := s.db.QueryRow(`
+SELECT COUNT(*) FILTER (WHERE type = 'table'), COUNT(*) FILTER (WHERE type = 'trigger')
+FROM sqlite_master
+WHERE name IN ('messages_fts', 'messages_fts_ai', 'messages_fts_ad', 'messages_fts_au')`).Scan(&tables, &triggers)
+	if err != nil {
+		return err
+	}
+
+	const index = `
+CREATE VIRTUAL TABLE IF NOT EXISTS messages_fts USING fts5(body, content='messages', content_rowid='rowid');
+CREATE TRIGGER IF NOT EXISTS messages_fts_ai AFTER INSERT ON messages BEGIN
+	INSERT INTO messages_fts(rowid, body) VALUES (new.rowid, new.body);
+END;
+CREATE TRIGGER IF NOT EXISTS messages_fts_ad AFTER DELETE ON messages BEGIN
+	INSERT INTO messages_fts(messages_fts, rowid, body) VALUES ('delete', old.rowid, old.body);
+END;
+CREATE TRIGGER IF NOT EXISTS messages_fts_au AFTER UPDATE OF body ON messages BEGIN
+	INSERT INTO messages_fts(messages_fts, rowid, body) VALUES ('delete', old.rowid, old.body);
+	INSERT INTO messages_fts(rowid, body) VALUES (new.rowid, new.body);
+END;`
+
+	if _, err := s.db.Exec(index); err != nil {
+		return err
+	}
+	s.fts = true
+
+	if tables == 0 || triggers < 3 {
+		_, err = s.db.Exec(`INSERT INTO messages_fts(messages_fts) VALUES ('rebuild')`)
+	}
+	return err
+}
+
+// Search looks for messages across all chats, newest first.
+func (s *Store) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	if !s.acquire() {
+		return nil, nil
+	}
+	defer s.release()
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	if s.fts {
+		return s.searchFTS(ctx, query, limit)
+	}
+	return s.searchLike(ctx, query, limit)
+}
+
+func (s *Store) searchFTS(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT m.chat_jid, m.id, m.sender_jid, m.ts, m.from_me, m.type, m.body, m.status, m.edited_at,
+	snippet(messages_fts, 0, ?, ?, '…', 12)
+FROM messages_fts
+JOIN messages m ON m.rowid = messages_fts.rowid
+WHERE messages_fts MATCH ?
+ORDER BY m.ts DESC
+LIMIT ?`, HighlightStart, HighlightEnd, ftsQuery(query), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var (
+			msg     Message
+			sender  sql.NullString
+			msgType sql.NullString
+			body    sql.NullString
+			ts      sql.NullInt64
+			fromMe  sql.NullInt64
+			status  sql.NullInt64
+			edited  sql.NullInt64
+			snippet sql.NullString
+		)
+		if err := rows.Scan(&msg.ChatJID, &msg.ID, &sender, &ts, &fromMe, &msgType, &body, &status, &edited, &snippet); err != nil {
+			return nil, err
+		}
+
+		msg.SenderJID = sender.String
+		msg.Type = msgType.String
+		msg.Body = body.String
+		msg.FromMe = fromMe.Int64 != 0
+		msg.Status = MessageStatus(status.Int64)
+		if ts.Valid {
+			msg.Timestamp = time.Unix(ts.Int64, 0)
+		}
+		if edited.Int64 > 0 {
+			msg.EditedAt = time.Unix(edited.Int64, 0)
+		}
+
+		results = append(results, SearchResult{Message: msg, Snippet: snippet.String})
+	}
+
+	return results, rows.Err()
+}
+
+func (s *Store) searchLike(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+	rows, err := s.db.QueryContext(ctx, `
+SELECT chat_jid, id, sender_jid, ts, from_me, type, body, raw, status, edited_at FROM messages
+WHERE body LIKE '%' || ? || '%' ESCAPE '\'
+ORDER BY ts DESC
+LIMIT ?`, escaped, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages, err := scanMessages(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(messages))
+	for _, msg := range messages {
+		msg.Raw = nil
+		results = append(results, SearchResult{Message: msg, Snippet: likeSnippet(msg.Body, query)})
+	}
+	return results, nil
+}
+
+// ftsQuery quotes every term so user input cannot use FTS5 syntax, and lets
+// the last term match as a prefix while the user is still typing.
+func ftsQuery(query string) string {
+	terms := strings.Fields(query)
+	for i, term := range terms {
+		terms[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(terms, " ") + "*"
+}
+
+func likeSnippet(body, query string) string {
+	idx := strings.Index(strings.ToLower(body), strings.ToLower(query))
+	if idx < 0 || len(strings.ToLower(body)) != len(body) {
+		return body
+	}
+
+	end := idx + len(query)
+	start := idx
+	for i := 0; i < snippetContext && start > 0; i++ {
+		_, size := utf8.DecodeLastRuneInString(body[:start])
+		start -= size
+	}
+	stop := end
+	for i := 0; i < snippetContext && stop < len(body); i++ {
+		_, size := utf8.DecodeRuneInString(body[stop:])
+		stop += size
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	b.WriteString(body[start:idx])
+	b.WriteString(HighlightStart)
+	b.WriteString(body[idx:end])
+	b.WriteString(HighlightEnd)
+	b.WriteString(body[end:stop])
+	if stop < len(body) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
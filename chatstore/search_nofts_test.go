@@ -0,0 +1,76 @@
+//go:build !sqlite_fts5
+
+package chatstore
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestOpenFTSDatabaseWithoutFTS opens a chat database created by an FTS5
+// build. SQLite cannot create the fts5 table here, so the test writes its
+// schema row and the triggers as that build left them.
+func TestOpenFTSDatabaseWithoutFTS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chats.db")
+	s, err := New(path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	s.Close()
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+PRAGMA writable_schema = ON;
+INSERT INTO sqlite_master (type, name, tbl_name, rootpage, sql) VALUES ('table', 'messages_fts', 'messages_fts', 0,
+	'CREATE VIRTUAL TABLE messages_fts USING fts5(body, content=''messages'', content_rowid=''rowid'')');
+PRAGMA writable_schema = OFF;`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+CREATE TRIGGER messages_fts_ai AFTER INSERT ON messages BEGIN
+	INSERT INTO messages_fts(rowid, body) VALUES (new.rowid, new.body);
+END;
+CREATE TRIGGER messages_fts_ad AFTER DELETE ON messages BEGIN
+	INSERT INTO messages_fts(messages_fts, rowid, body) VALUES ('delete', old.rowid, old.body);
+END;
+CREATE TRIGGER messages_fts_au AFTER UPDATE OF body ON messages BEGIN
+	INSERT INTO messages_fts(messages_fts, rowid, body) VALUES ('delete', old.rowid, old.body);
+	INSERT INTO messages_fts(rowid, body) VALUES (new.rowid, new.body);
+END;`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = New(path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer s.Close()
+
+	ctx := context.Background()
+	err = s.SaveMessages(ctx, []Message{{ChatJID: "a@s.whatsapp.net", ID: "1", Timestamp: time.Unix(1700000000, 0), Body: "hello"}})
+	if err != nil {
+		t.Fatalf("SaveMessages: %v", err)
+	}
+	if err := s.EditMessage(ctx, "a@s.whatsapp.net", "1", "hello again", time.Unix(1700000001, 0)); err != nil {
+		t.Fatalf("EditMessage: %v", err)
+	}
+	if got := searchIDs(t, s, "again"); !slices.Equal(got, []string{"1"}) {
+		t.Errorf("Search = %v, want [1]", got)
+	}
+}
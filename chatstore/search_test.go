@@ -0,0 +1,148 @@
+package chatstore
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFTSQuery(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{"hello", `"hello"*`},
+		{"  hello   world ", `"hello" "world"*`},
+		{`say "hi"`, `"say" """hi"""*`},
+		{"a AND b", `"a" "AND" "b"*`},
+		{"-x NEAR(y) body:z", `"-x" "NEAR(y)" "body:z"*`},
+	} {
+		if got := ftsQuery(tc.query); got != tc.want {
+			t.Errorf("ftsQuery(%q) = %s, want %s", tc.query, got, tc.want)
+		}
+	}
+}
+
+func saveSearchMessages(t *testing.T, s *Store) {
+	t.Helper()
+
+	base := time.Unix(1700000000, 0)
+	err := s.SaveMessages(context.Background(), []Message{
+		{ChatJID: "a@s.whatsapp.net", ID: "1", Timestamp: base, Body: "hello there"},
+		{ChatJID: "a@s.whatsapp.net", ID: "2", Timestamp: base.Add(time.Second), Body: `she said "AND" then (left)`},
+		{ChatJID: "b@s.whatsapp.net", ID: "3", Timestamp: base.Add(2 * time.Second), Body: "50% off"},
+		{ChatJID: "b@s.whatsapp.net", ID: "4", Timestamp: base.Add(3 * time.Second), Body: "500 left"},
+		{ChatJID: "b@s.whatsapp.net", ID: "5", Timestamp: base.Add(4 * time.Second), Body: "snake_case name"},
+	})
+	if err != nil {
+		t.Fatalf("SaveMessages: %v", err)
+	}
+}
+
+func searchIDs(t *testing.T, s *Store, query string) []string {
+	t.Helper()
+
+	results, err := s.Search(context.Background(), query, 0)
+	if err != nil {
+		t.Fatalf("Search(%q): %v", query, err)
+	}
+	ids := make([]string, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.Message.ID)
+	}
+	return ids
+}
+
+// TestSearch runs on FTS5 when the build has it, and on LIKE otherwise.
+func TestSearch(t *testing.T) {
+	s := newTestStore(t)
+	saveSearchMessages(t, s)
+	t.Logf("fts: %v", s.fts)
+
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"hello", []string{"1"}},
+		{"hel", []string{"1"}},
+		{`"AND"`, []string{"2"}},
+		{"", nil},
+	} {
+		if got := searchIDs(t, s, tc.query); !slices.Equal(got, tc.want) {
+			t.Errorf("Search(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+
+	// Queries that are FTS5 syntax must not fail.
+	for _, query := range []string{`"`, "AND", "NOT", "a OR", "(", "(left)", "*", "body:x", "NEAR(a b)", "-"} {
+		if _, err := s.Search(context.Background(), query, 0); err != nil {
+			t.Errorf("Search(%q): %v", query, err)
+		}
+	}
+}
+
+func TestSearchLikeFallback(t *testing.T) {
+	s := newTestStore(t)
+	saveSearchMessages(t, s)
+	s.fts = false
+
+	for _, tc := range []struct {
+		query string
+		want  []string
+	}{
+		{"ELLO", []string{"1"}},
+		{"50%", []string{"3"}},
+		{"e_c", []string{"5"}},
+		{"left", []string{"4", "2"}},
+		{`\`, nil},
+	} {
+		if got := searchIDs(t, s, tc.query); !slices.Equal(got, tc.want) {
+			t.Errorf("Search(%q) = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+
+	results, err := s.Search(context.Background(), "there", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "hello " + HighlightStart + "there" + HighlightEnd; len(results) != 1 || results[0].Snippet != want {
+		t.Errorf("snippet = %+v, want %q", results, want)
+	}
+}
+
+// TestSearchRebuildsStaleIndex reopens a database whose triggers a build
+// without FTS5 dropped, so messages saved meanwhile are missing from the
+// index.
+func TestSearchRebuildsStaleIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chats.db")
+	s, err := New(path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if !s.fts {
+		s.Close()
+		t.Skip("SQLite built without FTS5")
+	}
+
+	_, err = s.db.Exec(`
+DROP TRIGGER messages_fts_ai;
+DROP TRIGGER messages_fts_ad;
+DROP TRIGGER messages_fts_au;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveSearchMessages(t, s)
+	s.Close()
+
+	s, err = New(path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer s.Close()
+
+	if got := searchIDs(t, s, "hello"); !slices.Equal(got, []string{"1"}) {
+		t.Errorf("Search = %v, want [1]", got)
+	}
+}
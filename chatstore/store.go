@@ -3,6 +3,8 @@ package chatstore
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/9d4/watui/roomlist"
@@ -10,7 +12,13 @@ import (
 )
 
 type Store struct {
-	db *sql.DB
+	db  *sql.DB
+	fts bool
+
+	// mu lets Close wait for running operations; db itself is never
+	// replaced, so goroutines of an account switched away from stay safe.
+	mu       sync.RWMutex
+	isClosed bool
 }
 
 type SyncState struct {
@@ -62,23 +70,127 @@ CREATE TABLE IF NOT EXISTS sync_state (
 	updated_at INTEGER
 );`
 
-	_, err := s.db.Exec(chats + syncState)
+	const messages = `
+CREATE TABLE IF NOT EXISTS messages (
+	chat_jid TEXT NOT NULL,
+	id TEXT NOT NULL,
+	sender_jid TEXT,
+	ts INTEGER,
+	from_me INTEGER,
+	type TEXT,
+	body TEXT,
+	raw BLOB,
+	PRIMARY KEY (chat_jid, id)
+);
+CREATE INDEX IF NOT EXISTS messages_chat_ts ON messages (chat_jid, ts, id);`
+
+	const receipts = `
+CREATE TABLE IF NOT EXISTS message_receipts (
+	chat_jid TEXT NOT NULL,
+	message_id TEXT NOT NULL,
+	participant TEXT NOT NULL,
+	delivered_at INTEGER,
+	read_at INTEGER,
+	played_at INTEGER,
+	PRIMARY KEY (chat_jid, message_id, participant)
+);`
+
+	const reactions = `
+CREATE TABLE IF NOT EXISTS message_reactions (
+	chat_jid TEXT NOT NULL,
+	message_id TEXT NOT NULL,
+	sender_jid TEXT NOT NULL,
+	emoji TEXT NOT NULL,
+	ts INTEGER,
+	PRIMARY KEY (chat_jid, message_id, sender_jid)
+);`
+
+	if _, err := s.db.Exec(chats + syncState + messages + receipts + reactions); err != nil {
+		return err
+	}
+
+	if err := s.ensureColumn("messages", "status", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := s.ensureColumn("messages", "edited_at", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	for _, column := range []string{"pinned", "archived", "muted_until"} {
+		if err := s.ensureColumn("chat_rooms", column, "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+	}
+
+	return s.ensureSearchIndex()
+}
+
+// ensureColumn adds a column that older databases were created without.
+func (s *Store) ensureColumn(table, column, definition string) error {
+	rows, err := s.db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
 	return err
 }
 
+// acquire holds the store open for one operation and reports whether it
+// is. A missing or closed store makes every operation a no-op; otherwise
+// the caller calls release when done, and Close waits for it.
+func (s *Store) acquire() bool {
+	if s == nil || s.db == nil {
+		return false
+	}
+	s.mu.RLock()
+	if s.isClosed {
+		s.mu.RUnlock()
+		return false
+	}
+	return true
+}
+
+func (s *Store) release() {
+	s.mu.RUnlock()
+}
+
+// Close closes the database once the operations already running are done.
+// The store can still be called afterwards, doing nothing.
 func (s *Store) Close() error {
 	if s == nil || s.db == nil {
 		return nil
 	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.isClosed {
+		return nil
+	}
+	s.isClosed = true
 	return s.db.Close()
 }
 
 func (s *Store) LoadAll(ctx context.Context) ([]roomlist.Room, SyncState, error) {
-	if s == nil {
+	if !s.acquire() {
 		return nil, SyncState{}, nil
 	}
+	defer s.release()
 
-	rows, err := s.db.QueryContext(ctx, `SELECT jid, title, last_message, last_ts, unread_count FROM chat_rooms ORDER BY last_ts DESC, jid ASC`)
+	rows, err := s.db.QueryContext(ctx, `SELECT jid, title, last_message, last_ts, unread_count, pinned, archived, muted_until FROM chat_rooms ORDER BY last_ts DESC, jid ASC`)
 	if err != nil {
 		return nil, SyncState{}, err
 	}
@@ -90,8 +202,10 @@ func (s *Store) LoadAll(ctx context.Context) ([]roomlist.Room, SyncState, error)
 			jid, title, lastMessage sql.NullString
 			lastTS                  sql.NullInt64
 			unread                  sql.NullInt64
+			pinned, archived        bool
+			mutedUntil              int64
 		)
-		if err := rows.Scan(&jid, &title, &lastMessage, &lastTS, &unread); err != nil {
+		if err := rows.Scan(&jid, &title, &lastMessage, &lastTS, &unread, &pinned, &archived, &mutedUntil); err != nil {
 			return nil, SyncState{}, err
 		}
 
@@ -106,6 +220,11 @@ func (s *Store) LoadAll(ctx context.Context) ([]roomlist.Room, SyncState, error)
 			LastMessage: lastMessage.String,
 			Time:        ts,
 			UnreadCount: int(unread.Int64),
+			Pinned:      pinned,
+			Archived:    archived,
+		}
+		if mutedUntil > 0 {
+			room.MutedUntil = time.Unix(mutedUntil, 0)
 		}
 		rooms = append(rooms, room)
 	}
@@ -132,10 +251,11 @@ func (s *Store) LoadAll(ctx context.Context) ([]roomlist.Room, SyncState, error)
 	return rooms, state, err
 }
 
-func (s *Store) PersistHistory(ctx context.Context, rooms []roomlist.Room, state SyncState) error {
-	if s == nil {
+func (s *Store) PersistHistory(ctx context.Context, rooms []roomlist.Room, messages []Message, state SyncState) error {
+	if !s.acquire() {
 		return nil
 	}
+	defer s.release()
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -149,8 +269,8 @@ func (s *Store) PersistHistory(ctx context.Context, rooms []roomlist.Room, state
 
 	if len(rooms) > 0 {
 		stmt, errPrepare := tx.PrepareContext(ctx, `
-INSERT INTO chat_rooms (jid, title, last_message, last_ts, unread_count, updated_at)
-VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO chat_rooms (jid, title, last_message, last_ts, unread_count, pinned, archived, muted_until, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(jid) DO UPDATE SET
 	title=excluded.title,
 	last_message=excluded.last_message,
@@ -170,6 +290,9 @@ ON CONFLICT(jid) DO UPDATE SET
 				room.LastMessage,
 				room.Time.Unix(),
 				room.UnreadCount,
+				boolToInt(room.Pinned),
+				boolToInt(room.Archived),
+				mutedUnix(room.MutedUntil),
 				time.Now().Unix(),
 			)
 			if err != nil {
@@ -178,6 +301,10 @@ ON CONFLICT(jid) DO UPDATE SET
 		}
 	}
 
+	if err = insertMessages(ctx, tx, messages); err != nil {
+		return err
+	}
+
 	state.InProgress = state.Progress < 100
 	state.UpdatedAt = time.Now()
 
@@ -199,20 +326,21 @@ ON CONFLICT(id) DO UPDATE SET
 }
 
 func (s *Store) UpsertRoom(ctx context.Context, room roomlist.Room) error {
-	if s == nil {
+	if !s.acquire() {
 		return nil
 	}
+	defer s.release()
 
 	_, err := s.db.ExecContext(ctx, `
-INSERT INTO chat_rooms (jid, title, last_message, last_ts, unread_count, updated_at)
-VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO chat_rooms (jid, title, last_message, last_ts, unread_count, pinned, archived, muted_until, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(jid) DO UPDATE SET
 	title=excluded.title,
 	last_message=excluded.last_message,
 	last_ts=excluded.last_ts,
 	unread_count=excluded.unread_count,
 	updated_at=excluded.updated_at
-`, room.ID, room.Title, room.LastMessage, room.Time.Unix(), room.UnreadCount, time.Now().Unix())
+`, room.ID, room.Title, room.LastMessage, room.Time.Unix(), room.UnreadCount, boolToInt(room.Pinned), boolToInt(room.Archived), mutedUnix(room.MutedUntil), time.Now().Unix())
 	return err
 }
 
@@ -222,3 +350,57 @@ func boolToInt(b bool) int {
 	}
 	return 0
 }
+
+func (s *Store) SetUnreadCount(ctx context.Context, jid string, count int) error {
+	if !s.acquire() {
+		return nil
+	}
+	defer s.release()
+
+	_, err := s.db.ExecContext(ctx, `
+UPDATE chat_rooms SET unread_count = ?, updated_at = ? WHERE jid = ?`, count, time.Now().Unix(), jid)
+	return err
+}
+
+// SetChatState stores the pinned, archived and muted state of a chat. App
+// state sync owns it, so saving the room itself leaves it alone.
+func (s *Store) SetChatState(ctx context.Context, room roomlist.Room) error {
+	if !s.acquire() {
+		return nil
+	}
+	defer s.release()
+
+	_, err := s.db.ExecContext(ctx, `
+UPDATE chat_rooms SET pinned = ?, archived = ?, muted_until = ?, updated_at = ? WHERE jid = ?`,
+		boolToInt(room.Pinned), boolToInt(room.Archived), mutedUnix(room.MutedUntil), time.Now().Unix(), room.ID)
+	return err
+}
+
+func mutedUnix(until time.Time) int64 {
+	if until.IsZero() {
+		return 0
+	}
+	return until.Unix()
+}
+
+// Clear deletes every chat, message and receipt of the store, for an
+// account that was logged out.
+func (s *Store) Clear(ctx context.Context) error {
+	if !s.acquire() {
+		return nil
+	}
+	defer s.release()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"message_reactions", "message_receipts", "messages", "chat_rooms", "sync_state"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,117 @@
+package chatstore
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/9d4/watui/roomlist"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	s, err := New(filepath.Join(t.TempDir(), "chats.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// newStoreFrom opens a store on a database an earlier version created with
+// schema.
+func newStoreFrom(t *testing.T, schema string) *Store {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "chats.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(schema)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := New(path)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// firstMessagesTable is the messages table as chat history was first
+// stored.
+const firstMessagesTable = `
+CREATE TABLE messages (
+	chat_jid TEXT NOT NULL,
+	id TEXT NOT NULL,
+	sender_jid TEXT,
+	ts INTEGER,
+	from_me INTEGER,
+	type TEXT,
+	body TEXT,
+	raw BLOB,
+	PRIMARY KEY (chat_jid, id)
+);`
+
+func TestEnsureSchemaAddsChatState(t *testing.T) {
+	s := newStoreFrom(t, `
+CREATE TABLE chat_rooms (
+	jid TEXT PRIMARY KEY,
+	title TEXT,
+	last_message TEXT,
+	last_ts INTEGER,
+	unread_count INTEGER,
+	updated_at INTEGER
+);
+INSERT INTO chat_rooms VALUES ('a@s.whatsapp.net', 'Ann', 'hi', 100, 2, 100);`)
+	ctx := context.Background()
+
+	rooms, _, err := s.LoadAll(ctx)
+	if err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	if len(rooms) != 1 || rooms[0].UnreadCount != 2 || rooms[0].Pinned || rooms[0].Archived || !rooms[0].MutedUntil.IsZero() {
+		t.Errorf("rooms = %+v", rooms)
+	}
+}
+
+func TestUpsertRoomKeepsChatState(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	room := roomlist.Room{ID: "a@s.whatsapp.net", Title: "Ann", Time: time.Unix(100, 0)}
+	if err := s.UpsertRoom(ctx, room); err != nil {
+		t.Fatalf("UpsertRoom: %v", err)
+	}
+
+	state := room
+	state.Pinned = true
+	state.MutedUntil = time.Unix(5000, 0)
+	if err := s.SetChatState(ctx, state); err != nil {
+		t.Fatalf("SetChatState: %v", err)
+	}
+
+	room.LastMessage = "new"
+	if err := s.UpsertRoom(ctx, room); err != nil {
+		t.Fatalf("UpsertRoom: %v", err)
+	}
+
+	rooms, _, err := s.LoadAll(ctx)
+	if err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	if len(rooms) != 1 {
+		t.Fatalf("rooms = %+v", rooms)
+	}
+	got := rooms[0]
+	if got.LastMessage != "new" || !got.Pinned || got.Archived || !got.MutedUntil.Equal(state.MutedUntil) {
+		t.Errorf("room = %+v", got)
+	}
+}
@@ -1,23 +1,69 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/config"
+	"github.com/9d4/watui/i18n"
 	"github.com/9d4/watui/internal/tui"
+	"github.com/9d4/watui/notify"
+	"github.com/9d4/watui/preview"
 	"github.com/9d4/watui/wa"
 	tea "github.com/charmbracelet/bubbletea"
+	"go.mau.fi/whatsmeow/types"
 )
 
 func main() {
 	devMode := flag.Bool("dev", false, "enable developer notifications")
+	dataDir := flag.String("data-dir", "", "directory for sessions, chats and logs (env WATUI_DATA_DIR)")
+	configFile := flag.String("config", "", "path to config.toml (env WATUI_CONFIG)")
 	flag.Parse()
 
+	cfg, err := config.Load(config.Overrides{ConfigFile: *configFile, DataDir: *dataDir})
+	if err != nil {
+		log.Fatalf("cannot load config: %v", err)
+	}
+	if err := cfg.EnsureDirs(); err != nil {
+		log.Fatalf("cannot create data directory: %v", err)
+	}
+
+	switch from, moved, err := cfg.AdoptLegacySession(); {
+	case err != nil:
+		log.Fatalf("cannot look for an earlier session: %v", err)
+	case moved:
+		log.Printf("moved the session database %s to %s", from, cfg.SessionDB)
+	case from != "":
+		log.Printf("using the session database %s, which cannot be moved to the data directory", from)
+	}
+
+	i18n.SetLanguage(i18n.Detect(cfg.Language))
+
+	imagePreview, err := preview.ParseProtocol(cfg.ImagePreview)
+	if err != nil {
+		log.Fatalf("invalid image_preview: %v", err)
+	}
+
+	notifyMethod, err := notify.ParseMethod(cfg.Notify)
+	if err != nil {
+		log.Fatalf("invalid notify: %v", err)
+	}
+
+	idleTimeout, err := time.ParseDuration(cfg.IdleTimeout)
+	if err != nil {
+		log.Fatalf("invalid idle_timeout: %v", err)
+	}
+
 	if len(os.Getenv("DEBUG")) > 0 {
-		f, err := tea.LogToFile("debug.log", "debug")
+		f, err := tea.LogToFile(cfg.DebugLog, "debug")
 		if err != nil {
 			fmt.Println("fatal:", err)
 			os.Exit(1)
@@ -25,21 +71,75 @@ func main() {
 		defer f.Close()
 	}
 
-	logger, err := wa.CreateFileLogger("watui.log")
+	logger, err := wa.CreateFileLogger(cfg.LogFile)
 	if err != nil {
 		log.Fatalf("cannot open file for log: %v", err)
 	}
 
-	roomStore, err := chatstore.New("watui.db")
-	if err != nil {
-		log.Fatalf("cannot init room store: %v", err)
+	// Stores open from tea.Cmd goroutines, so the list closed on exit is
+	// guarded.
+	var (
+		storesMu sync.Mutex
+		stores   []*chatstore.Store
+	)
+	defer func() {
+		storesMu.Lock()
+		defer storesMu.Unlock()
+		for _, s := range stores {
+			s.Close()
+		}
+	}()
+
+	openStore := func(account types.JID) (*chatstore.Store, error) {
+		path := cfg.ChatDB(account.User)
+		_, statErr := os.Stat(path)
+		s, err := chatstore.New(path)
+		if err != nil {
+			return nil, err
+		}
+		// A new store takes over the rooms earlier versions kept in the
+		// session database.
+		if errors.Is(statErr, fs.ErrNotExist) {
+			if err := s.ImportLegacy(context.Background(), cfg.SessionDB); err != nil {
+				logger.Warn().Err(err).Msg("cannot import chats from the session database")
+			}
+		}
+		storesMu.Lock()
+		stores = append(stores, s)
+		storesMu.Unlock()
+		return s, nil
 	}
-	defer roomStore.Close()
 
-	m := wa.NewManager(logger)
-	t := tui.New(m, roomStore, *devMode)
-	p := tea.NewProgram(t)
-	if _, err := p.Run(); err != nil {
+	var manager *wa.Manager
+	defer func() {
+		manager.Close()
+	}()
+
+	openManager := func() (*wa.Manager, error) {
+		m, err := wa.NewManager(logger, cfg.SessionDB)
+		if err != nil {
+			return nil, err
+		}
+		manager = m
+		return m, nil
+	}
+
+	t := tui.New(openManager, openStore, tui.Options{
+		DevMode:            *devMode,
+		MediaDir:           cfg.MediaDir,
+		OpenCommand:        cfg.OpenCommand,
+		ImagePreview:       imagePreview,
+		Notify:             notifyMethod,
+		HideTyping:         cfg.HideTyping,
+		IdleTimeout:        idleTimeout,
+		OnlineInBackground: cfg.OnlineInBackground,
+	})
+	p := tea.NewProgram(t, tea.WithReportFocus())
+	final, err := p.Run()
+	if s, ok := final.(interface{ Shutdown() }); ok {
+		s.Shutdown()
+	}
+	if err != nil {
 		log.Fatal("Failed to start watui", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,274 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const appName = "watui"
+
+// Config holds the resolved file locations and preferences. Relative paths
+// are resolved against DataDir.
+type Config struct {
+	DataDir   string `toml:"data_dir"`
+	SessionDB string `toml:"session_db"`
+	LogFile   string `toml:"log_file"`
+	DebugLog  string `toml:"debug_log"`
+	Language  string `toml:"language"`
+
+	// MediaDir caches downloaded attachments. OpenCommand opens them; it
+	// defaults to xdg-open and takes the file as "{}" or last argument.
+	MediaDir    string `toml:"media_dir"`
+	OpenCommand string `toml:"open_command"`
+
+	// ImagePreview is auto, kitty, sixel, halfblocks or text.
+	ImagePreview string `toml:"image_preview"`
+
+	// Notify is auto, bell, osc9, osc777, dbus or off.
+	Notify string `toml:"notify"`
+
+	// HideTyping stops telling chats when we are typing.
+	HideTyping bool `toml:"hide_typing"`
+
+	// IdleTimeout is how long without input before we show as unavailable;
+	// "0" never does. OnlineInBackground stays available while the terminal
+	// is not focused.
+	IdleTimeout        string `toml:"idle_timeout"`
+	OnlineInBackground bool   `toml:"online_in_background"`
+
+	// defaultSession is set when SessionDB was not configured.
+	defaultSession bool
+}
+
+// legacySessionDB is where watui kept its session before it had a data
+// directory: in the working directory.
+const legacySessionDB = "watui.db"
+
+// Overrides are values given on the command line. Empty fields are ignored.
+type Overrides struct {
+	ConfigFile string
+	DataDir    string
+}
+
+// Load resolves the configuration in order of precedence: built-in defaults,
+// the config file, WATUI_* environment variables and finally overrides.
+func Load(overrides Overrides) (Config, error) {
+	path, explicit := configPath(overrides)
+
+	var cfg Config
+	if path != "" {
+		_, err := toml.DecodeFile(path, &cfg)
+		switch {
+		case err == nil:
+		case errors.Is(err, fs.ErrNotExist) && !explicit:
+		default:
+			return Config{}, fmt.Errorf("cannot read config %s: %w", path, err)
+		}
+	}
+
+	applyEnv(&cfg)
+	if overrides.DataDir != "" {
+		cfg.DataDir = overrides.DataDir
+	}
+
+	if cfg.DataDir == "" {
+		cfg.DataDir = defaultDataDir()
+	}
+	cfg.DataDir = expandHome(cfg.DataDir)
+
+	cfg.defaultSession = cfg.SessionDB == ""
+	cfg.SessionDB = cfg.resolve(cfg.SessionDB, "watui.db")
+	cfg.LogFile = cfg.resolve(cfg.LogFile, "watui.log")
+	cfg.DebugLog = cfg.resolve(cfg.DebugLog, "debug.log")
+	cfg.MediaDir = cfg.resolve(cfg.MediaDir, "media")
+
+	if cfg.IdleTimeout == "" {
+		cfg.IdleTimeout = "5m"
+	}
+
+	return cfg, nil
+}
+
+// EnsureDirs creates the data directory and the parent directory of every
+// configured file.
+func (c Config) EnsureDirs() error {
+	dirs := []string{
+		c.DataDir,
+		filepath.Dir(c.SessionDB),
+		filepath.Dir(c.LogFile),
+		filepath.Dir(c.DebugLog),
+		c.MediaDir,
+	}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdoptLegacySession carries a session from before the data directory
+// over, so upgrading does not unpair the device. When SessionDB is the
+// default and missing while ./watui.db exists, that file moves into place
+// with its WAL files. When it cannot move, SessionDB points at it instead.
+// from is the legacy file taken over, or "" when there was none.
+func (c *Config) AdoptLegacySession() (from string, moved bool, err error) {
+	if !c.defaultSession {
+		return "", false, nil
+	}
+
+	legacy, err := filepath.Abs(legacySessionDB)
+	if err != nil || legacy == c.SessionDB {
+		return "", false, nil
+	}
+	if exists, err := fileExists(c.SessionDB); exists || err != nil {
+		return "", false, err
+	}
+	if exists, err := fileExists(legacy); !exists || err != nil {
+		return "", false, err
+	}
+
+	if err := moveDB(legacy, c.SessionDB); err != nil {
+		c.SessionDB = legacy
+		return legacy, false, nil
+	}
+	return legacy, true, nil
+}
+
+// moveDB renames a SQLite database and its -wal and -shm files, putting
+// back what moved when one of them cannot.
+func moveDB(from, to string) error {
+	var moved []string
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		err := os.Rename(from+suffix, to+suffix)
+		if err == nil {
+			moved = append(moved, suffix)
+			continue
+		}
+		if suffix != "" && errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		for _, done := range moved {
+			_ = os.Rename(to+done, from+done)
+		}
+		return err
+	}
+	return nil
+}
+
+func fileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// ChatDB returns the chat store path of the account with the given user ID.
+func (c Config) ChatDB(account string) string {
+	return filepath.Join(c.DataDir, fmt.Sprintf("chats-%s.db", account))
+}
+
+func (c Config) resolve(path, fallback string) string {
+	if path == "" {
+		path = fallback
+	}
+	path = expandHome(path)
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(c.DataDir, path)
+}
+
+func configPath(overrides Overrides) (string, bool) {
+	if overrides.ConfigFile != "" {
+		return expandHome(overrides.ConfigFile), true
+	}
+	if env := os.Getenv("WATUI_CONFIG"); env != "" {
+		return expandHome(env), true
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", false
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, appName, "config.toml"), false
+}
+
+func applyEnv(cfg *Config) {
+	envs := []struct {
+		key   string
+		value *string
+	}{
+		{"WATUI_DATA_DIR", &cfg.DataDir},
+		{"WATUI_SESSION_DB", &cfg.SessionDB},
+		{"WATUI_LOG_FILE", &cfg.LogFile},
+		{"WATUI_DEBUG_LOG", &cfg.DebugLog},
+		{"WATUI_LANG", &cfg.Language},
+		{"WATUI_MEDIA_DIR", &cfg.MediaDir},
+		{"WATUI_OPEN_COMMAND", &cfg.OpenCommand},
+		{"WATUI_IMAGE_PREVIEW", &cfg.ImagePreview},
+		{"WATUI_NOTIFY", &cfg.Notify},
+		{"WATUI_IDLE_TIMEOUT", &cfg.IdleTimeout},
+	}
+
+	for _, env := range envs {
+		if v := os.Getenv(env.key); v != "" {
+			*env.value = v
+		}
+	}
+
+	flags := []struct {
+		key   string
+		value *bool
+	}{
+		{"WATUI_HIDE_TYPING", &cfg.HideTyping},
+		{"WATUI_ONLINE_IN_BACKGROUND", &cfg.OnlineInBackground},
+	}
+
+	for _, flag := range flags {
+		if v, err := strconv.ParseBool(os.Getenv(flag.key)); err == nil {
+			*flag.value = v
+		}
+	}
+}
+
+func defaultDataDir() string {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, appName)
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "."
+	}
+	return filepath.Join(home, ".local", "share", appName)
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
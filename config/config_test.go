@@ -0,0 +1,214 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var watuiEnv = []string{
+	"WATUI_CONFIG", "WATUI_DATA_DIR", "WATUI_SESSION_DB", "WATUI_LOG_FILE", "WATUI_DEBUG_LOG",
+	"WATUI_LANG", "WATUI_MEDIA_DIR", "WATUI_OPEN_COMMAND", "WATUI_IMAGE_PREVIEW", "WATUI_NOTIFY",
+	"WATUI_IDLE_TIMEOUT", "WATUI_HIDE_TYPING", "WATUI_ONLINE_IN_BACKGROUND",
+}
+
+// isolate points the home and XDG directories into a temp dir, clears the
+// WATUI_* variables and returns the temp dir.
+func isolate(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	for _, key := range watuiEnv {
+		t.Setenv(key, "")
+	}
+	return dir
+}
+
+func writeConfig(t *testing.T, dir, content string) {
+	t.Helper()
+
+	path := filepath.Join(dir, "config", appName, "config.toml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	dir := isolate(t)
+
+	cfg, err := Load(Overrides{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	data := filepath.Join(dir, "data", appName)
+	for name, got := range map[string]string{
+		"DataDir":     cfg.DataDir,
+		"SessionDB":   cfg.SessionDB,
+		"LogFile":     cfg.LogFile,
+		"DebugLog":    cfg.DebugLog,
+		"MediaDir":    cfg.MediaDir,
+		"IdleTimeout": cfg.IdleTimeout,
+	} {
+		want := map[string]string{
+			"DataDir":     data,
+			"SessionDB":   filepath.Join(data, "watui.db"),
+			"LogFile":     filepath.Join(data, "watui.log"),
+			"DebugLog":    filepath.Join(data, "debug.log"),
+			"MediaDir":    filepath.Join(data, "media"),
+			"IdleTimeout": "5m",
+		}[name]
+		if got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+	if got, want := cfg.ChatDB("6281234"), filepath.Join(data, "chats-6281234.db"); got != want {
+		t.Errorf("ChatDB = %q, want %q", got, want)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := isolate(t)
+	writeConfig(t, dir, `
+data_dir = "~/from-file"
+language = "en"
+log_file = "logs/watui.log"
+session_db = "/var/lib/watui/session.db"
+notify = "bell"
+hide_typing = true
+`)
+
+	cfg, err := Load(Overrides{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	fromFile := filepath.Join(dir, "home", "from-file")
+	if cfg.DataDir != fromFile || cfg.Language != "en" || cfg.Notify != "bell" || !cfg.HideTyping {
+		t.Errorf("file: %+v", cfg)
+	}
+	if want := filepath.Join(fromFile, "logs", "watui.log"); cfg.LogFile != want {
+		t.Errorf("LogFile = %q, want %q", cfg.LogFile, want)
+	}
+	if cfg.SessionDB != "/var/lib/watui/session.db" {
+		t.Errorf("SessionDB = %q", cfg.SessionDB)
+	}
+
+	fromEnv := filepath.Join(dir, "from-env")
+	t.Setenv("WATUI_DATA_DIR", fromEnv)
+	t.Setenv("WATUI_LANG", "id")
+	t.Setenv("WATUI_HIDE_TYPING", "false")
+
+	cfg, err = Load(Overrides{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.DataDir != fromEnv || cfg.Language != "id" || cfg.Notify != "bell" || cfg.HideTyping {
+		t.Errorf("env: %+v", cfg)
+	}
+	if want := filepath.Join(fromEnv, "logs", "watui.log"); cfg.LogFile != want {
+		t.Errorf("LogFile = %q, want %q", cfg.LogFile, want)
+	}
+
+	fromFlag := filepath.Join(dir, "from-flag")
+	cfg, err = Load(Overrides{DataDir: fromFlag})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.DataDir != fromFlag || cfg.Language != "id" {
+		t.Errorf("flag: %+v", cfg)
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := isolate(t)
+
+	other := filepath.Join(dir, "other.toml")
+	if err := os.WriteFile(other, []byte(`language = "en"`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, dir, `language = "id"`)
+
+	cfg, err := Load(Overrides{ConfigFile: other})
+	if err != nil || cfg.Language != "en" {
+		t.Errorf("Load(--config) = %q, %v; want en", cfg.Language, err)
+	}
+
+	t.Setenv("WATUI_CONFIG", other)
+	cfg, err = Load(Overrides{})
+	if err != nil || cfg.Language != "en" {
+		t.Errorf("Load(WATUI_CONFIG) = %q, %v; want en", cfg.Language, err)
+	}
+
+	// A missing file is an error only when asked for.
+	if _, err := Load(Overrides{ConfigFile: filepath.Join(dir, "missing.toml")}); err == nil {
+		t.Error("Load with a missing --config succeeded")
+	}
+	t.Setenv("WATUI_CONFIG", "")
+	if err := os.RemoveAll(filepath.Join(dir, "config")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(Overrides{}); err != nil {
+		t.Errorf("Load without a config file: %v", err)
+	}
+}
+
+func TestAdoptLegacySession(t *testing.T) {
+	dir := isolate(t)
+	work := filepath.Join(dir, "work")
+	if err := os.Mkdir(work, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(work)
+
+	for _, name := range []string{"watui.db", "watui.db-wal"} {
+		if err := os.WriteFile(filepath.Join(work, name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := Load(Overrides{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := cfg.EnsureDirs(); err != nil {
+		t.Fatalf("EnsureDirs: %v", err)
+	}
+
+	from, moved, err := cfg.AdoptLegacySession()
+	if err != nil || !moved || from != filepath.Join(work, "watui.db") {
+		t.Fatalf("AdoptLegacySession = %q, %v, %v", from, moved, err)
+	}
+	for _, suffix := range []string{"", "-wal"} {
+		data, err := os.ReadFile(cfg.SessionDB + suffix)
+		if err != nil || string(data) != "watui.db"+suffix {
+			t.Errorf("%s = %q, %v", cfg.SessionDB+suffix, data, err)
+		}
+		if _, err := os.Stat(filepath.Join(work, "watui.db"+suffix)); !os.IsNotExist(err) {
+			t.Errorf("legacy watui.db%s left behind: %v", suffix, err)
+		}
+	}
+
+	// With a session in the data directory, a legacy file stays put.
+	if err := os.WriteFile(filepath.Join(work, "watui.db"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if from, moved, err := cfg.AdoptLegacySession(); from != "" || moved || err != nil {
+		t.Errorf("AdoptLegacySession again = %q, %v, %v", from, moved, err)
+	}
+
+	// A configured session path is never replaced.
+	t.Setenv("WATUI_SESSION_DB", filepath.Join(dir, "session.db"))
+	cfg, err = Load(Overrides{})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if from, moved, err := cfg.AdoptLegacySession(); from != "" || moved || err != nil {
+		t.Errorf("AdoptLegacySession with session_db = %q, %v, %v", from, moved, err)
+	}
+}
@@ -0,0 +1,228 @@
+package i18n
+
+var catalogEN = map[string]string{
+	"accounts.active":    " · active",
+	"accounts.add":       "+ Add account",
+	"accounts.hint":      "Enter to select · n for a new account",
+	"accounts.hint_back": " · Esc to go back",
+	"accounts.title":     "Choose account",
+
+	"attach.hint":      "Enter to pick · h to go up · Esc to cancel",
+	"attach.staged":    "📎 %s (%s) · Enter to send, text becomes the caption · Esc to cancel",
+	"attach.title":     "Pick a file to send",
+	"attach.uploading": "Uploading %s...",
+
+	"chat.no_unread": "No new messages",
+	"chat.unread":    "%d unread messages",
+
+	"composer.editing":      "✎ Editing message · Esc to cancel",
+	"composer.hint_edit":    " · E to edit",
+	"composer.hint_idle":    "i to type · r to reply · j/k to scroll · / to search · Esc to close",
+	"composer.hint_info":    " · I for message info · Ctrl+G for group info",
+	"composer.hint_media":   " · o to open · s to save",
+	"composer.hint_mention": "Tab/Enter to pick · ↑/↓ to move · Esc to close",
+	"composer.hint_react":   " · e to react",
+	"composer.hint_revoke":  " · D to delete",
+	"composer.hint_typing":  "Enter to send · Ctrl+O to attach · Esc to go to history",
+	"composer.placeholder":  "Type a message...",
+	"composer.replying":     "↪ Replying to %s · Esc to cancel",
+
+	"conn.banned":          "Temporarily banned",
+	"conn.banned_until":    "Temporarily banned until %s",
+	"conn.connecting":      "Connecting…",
+	"conn.failure":         "connection refused (%d) %s",
+	"conn.hint_reconnect":  "Ctrl+R to reconnect",
+	"conn.offline":         "Offline",
+	"conn.online":          "Online",
+	"conn.queued":          "%d queued",
+	"conn.reconnecting":    "Reconnecting (attempt %d)…",
+	"conn.reconnecting_in": "Reconnecting (attempt %d) in %v",
+	"conn.replaced":        "session opened elsewhere",
+	"conn.stalled":         "Connection stalled…",
+
+	"err.account_not_found":  "account %s not found",
+	"err.attach":             "cannot attach file",
+	"err.change_denied":      "ignoring a change to message %s in %s by someone else",
+	"err.chat_state":         "cannot change the state of chat %s: %v",
+	"err.client_not_ready":   "client is not ready",
+	"err.client_unavailable": "client is unavailable",
+	"err.clear_chat_data":    "cannot clear chat data",
+	"err.connect":            "cannot connect",
+	"err.edit":               "cannot edit message in %s: %v",
+	"err.group_info":         "cannot load group info %s: %v",
+	"err.invalid_jid":        "invalid JID",
+	"err.load_chats":         "cannot load chats",
+	"err.load_contacts":      "cannot load contacts",
+	"err.load_messages":      "cannot load messages",
+	"err.load_older":         "cannot load older messages",
+	"err.load_receipts":      "cannot load message info",
+	"err.logout":             "cannot log out",
+	"err.mark_read":          "cannot mark %s as read: %v",
+	"err.media_download":     "cannot download attachment",
+	"err.media_missing":      "message is not in the store",
+	"err.media_open":         "cannot open attachment",
+	"err.media_save":         "cannot save attachment",
+	"err.notify":             "cannot send notification: %v",
+	"err.open_chat_data":     "cannot open chat data",
+	"err.open_session":       "cannot open session",
+	"err.pair_code":          "cannot create pairing code",
+	"err.pin_limit":          "at most %d chats can be pinned",
+	"err.presence":           "cannot send presence to %s: %v",
+	"err.qr_channel":         "cannot create QR channel",
+	"err.react":              "cannot send reaction to %s: %v",
+	"err.read_device":        "cannot read device",
+	"err.reconnect":          "cannot reconnect: %v",
+	"err.request_history":    "cannot request history for %s: %v",
+	"err.revoke":             "cannot delete message in %s: %v",
+	"err.save_chat":          "cannot save chat",
+	"err.save_history":       "cannot save history",
+	"err.save_message":       "cannot save message",
+	"err.save_reaction":      "cannot save reaction",
+	"err.search":             "cannot search messages",
+	"err.send":               "cannot send to %s: %v",
+	"err.session_missing":    "session store is unavailable",
+	"err.sync_contacts":      "cannot sync contacts",
+	"err.unknown":            "An unknown error occurred.",
+	"err.upload":             "cannot upload attachment",
+
+	"error.keys": "r to retry · q to quit",
+
+	"group.admins":       "Admins",
+	"group.created":      "Created %s",
+	"group.created_by":   "Created %s by %s",
+	"group.loading":      "Loading group info...",
+	"group.members":      "%d members",
+	"group.more":         "… and %d more",
+	"group.no_topic":     "No description",
+	"group.participants": "Members",
+
+	"hint.session_corrupt":  "The database is corrupt. Move the session file elsewhere and pair again.",
+	"hint.session_default":  "Check the data directory and the log file for details.",
+	"hint.session_locked":   "The database is used by another process. Close any other running watui and retry.",
+	"hint.session_readonly": "The database is not writable. Check the data directory permissions or use --data-dir.",
+	"hint.session_too_new":  "The database was created by a newer watui. Update watui first.",
+	"hint.session_upgrade":  "The database migration failed. Back up the session file and retry.",
+
+	"info.delivered_to": "Delivered to",
+	"info.hint":         "Esc to close",
+	"info.none":         "No receipts from group members yet.",
+	"info.read_by":      "Read by",
+	"info.title":        "Message info",
+
+	"logout.confirm_desc":   "This device will be unlinked from your WhatsApp account.",
+	"logout.confirm_keys":   "y to log out · n/Esc to cancel",
+	"logout.confirm_title":  "Log out of this account?",
+	"logout.data_cleared":   "The local chat history of this account was deleted.",
+	"logout.data_kept":      "The local chat history is still stored on this device.",
+	"logout.keys":           "Enter to pair again · a for accounts · q to quit",
+	"logout.keys_clear":     "Enter to pair again · d to delete the local chat history · a for accounts · q to quit",
+	"logout.reason_banned":  "This account was banned by WhatsApp.",
+	"logout.reason_locked":  "This account was locked by WhatsApp.",
+	"logout.reason_manual":  "You logged out of this device.",
+	"logout.reason_other":   "WhatsApp ended this session (%v).",
+	"logout.reason_removed": "This device was removed from the linked devices on your phone.",
+	"logout.title":          "Session ended",
+
+	"media.downloading": "Downloading attachment...",
+	"media.none":        "This message has no attachment",
+	"media.opened":      "Opening %s",
+	"media.save_hint":   "Enter to save · Esc to cancel",
+	"media.save_prompt": "Save to: ",
+	"media.saved":       "Saved to %s",
+
+	"messages.deleted": "This message was deleted",
+	"messages.edited":  "edited",
+	"messages.empty":   "No message history yet.",
+	"messages.failed":  "not sent",
+	"messages.loading": "Loading older messages...",
+	"messages.start":   "Start of conversation",
+
+	"pair.code_steps": "Linked devices › Link a device › Link with phone number instead",
+	"pair.enter_code": "Enter this code in WhatsApp",
+	"pair.scan_qr":    "Scan the code below with WhatsApp",
+
+	"phone.hint":    "Enter to request a code · Esc to go back",
+	"phone.invalid": "The number must be digits with the country code, for example 628123456789",
+	"phone.prompt":  "Enter your WhatsApp number including the country code:",
+	"phone.title":   "Pair with phone number",
+
+	"presence.last_seen": "last seen %s",
+	"presence.many":      "%d people %s",
+	"presence.online":    "online",
+	"presence.recording": "recording audio…",
+	"presence.today":     "today at %s",
+	"presence.typing":    "typing…",
+
+	"qr.failed":      "Pairing failed: %v",
+	"qr.multidevice": "Enable multi-device on your phone first",
+	"qr.outdated":    "Client is outdated, update whatsmeow",
+	"qr.status":      "Pairing status: %s",
+	"qr.success":     "Paired, waiting for sync",
+	"qr.timeout":     "Pairing session expired, try again",
+	"qr.unexpected":  "Unexpected pairing status, try again",
+
+	"quit.confirm_desc":  "%d messages queued while offline have not been sent and will be lost.",
+	"quit.confirm_keys":  "y to quit · n/Esc to cancel",
+	"quit.confirm_title": "Quit watui?",
+
+	"react.hint":  "1–6 or ←/→ and Enter to pick · x to remove · Esc to cancel",
+	"react.title": "React to %s",
+
+	"revoke.confirm": "Delete for everyone: %s?",
+	"revoke.keys":    "y to delete · n/Esc to cancel",
+
+	"rooms.archived":           "📦 Archived (%d)",
+	"rooms.archived_back":      "Esc to go back",
+	"rooms.archived_empty":     "No archived chats",
+	"rooms.archived_open":      "A to open",
+	"rooms.archived_title":     "📦 Archived",
+	"rooms.filter_placeholder": "Filter chats...",
+	"rooms.hint":               "Enter to open · / to filter · p to pin · m to mute · a to archive · A for archived",
+	"rooms.no_match":           "No matching chats",
+
+	"search.empty":       "No matching messages",
+	"search.hint":        "↑/↓ to select · Enter to open · Esc to close",
+	"search.placeholder": "Type a keyword...",
+	"search.prompt":      "Type to search across all chats",
+	"search.title":       "Search messages",
+
+	"sender.last":    "Latest",
+	"sender.me":      "Me",
+	"sender.unknown": "Unknown",
+
+	"status.connecting":      "Connecting...",
+	"status.connecting_wa":   "Connecting to WhatsApp...",
+	"status.logging_out":     "Logging out...",
+	"status.preparing":       "Preparing...",
+	"status.preparing_wa":    "Preparing WhatsApp session...",
+	"status.press_enter":     "Press Enter to start pairing",
+	"status.requesting_code": "Requesting pairing code...",
+	"status.retrying":        "Retrying...",
+
+	"summary.audio":           "🎵 Audio",
+	"summary.document":        "📄 %s",
+	"summary.ephemeral_days":  "⏱ Disappearing messages turned on: %d days",
+	"summary.ephemeral_hours": "⏱ Disappearing messages turned on: %d hours",
+	"summary.ephemeral_off":   "⏱ Disappearing messages turned off",
+	"summary.live_location":   "📍 Live location",
+	"summary.location":        "📍 Location %.3f, %.3f",
+	"summary.new":             "New message",
+	"summary.photo":           "📷 Photo",
+	"summary.sticker":         "💠 Sticker",
+	"summary.type":            "%s message",
+	"summary.video":           "🎥 Video",
+
+	"sync.continued":    "Background sync · %d%%",
+	"sync.data":         "Syncing data",
+	"sync.default_kind": "history",
+	"sync.info":         "Syncing %s · %d chats",
+	"sync.resuming":     "Resuming sync · %d%%",
+	"sync.title":        "Syncing history",
+	"sync.waiting":      "Waiting for WhatsApp data...",
+	"sync.waiting_wa":   "Waiting for history from WhatsApp...",
+
+	"welcome.alt_hint": "p to pair with a phone number · Ctrl+A to switch accounts",
+	"welcome.button":   " Continue ",
+	"welcome.desc":     "Use WhatsApp straight from your terminal.",
+	"welcome.hint":     "Press Enter to pair with a QR code.",
+}
@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const DefaultLanguage = "id"
+
+var catalogs = map[string]map[string]string{
+	"id": catalogID,
+	"en": catalogEN,
+}
+
+var current = DefaultLanguage
+
+// SetLanguage switches the active catalog. Unknown languages are ignored.
+func SetLanguage(lang string) {
+	if _, ok := catalogs[lang]; ok {
+		current = lang
+	}
+}
+
+func Language() string {
+	return current
+}
+
+// Detect picks a supported language from preferred, then from the LC_ALL,
+// LC_MESSAGES and LANG environment variables.
+func Detect(preferred string) string {
+	candidates := []string{preferred, os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")}
+	for _, candidate := range candidates {
+		if lang := normalize(candidate); lang != "" {
+			if _, ok := catalogs[lang]; ok {
+				return lang
+			}
+		}
+	}
+	return DefaultLanguage
+}
+
+// normalize turns locale names such as "en_US.UTF-8" into "en".
+func normalize(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "_.@-"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "c" || locale == "posix" {
+		return ""
+	}
+	return locale
+}
+
+// T returns the message for key in the active language, formatted with args.
+// Missing entries fall back to the default language, then to key itself.
+func T(key string, args ...any) string {
+	msg, ok := catalogs[current][key]
+	if !ok {
+		msg, ok = catalogs[DefaultLanguage][key]
+	}
+	if !ok {
+		msg = key
+	}
+
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
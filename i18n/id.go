@@ -0,0 +1,228 @@
+package i18n
+
+var catalogID = map[string]string{
+	"accounts.active":    " · aktif",
+	"accounts.add":       "+ Tambah akun",
+	"accounts.hint":      "Enter untuk memilih · n untuk akun baru",
+	"accounts.hint_back": " · Esc untuk kembali",
+	"accounts.title":     "Pilih akun",
+
+	"attach.hint":      "Enter untuk memilih · h untuk naik folder · Esc untuk batal",
+	"attach.staged":    "📎 %s (%s) · Enter untuk mengirim, teks menjadi keterangan · Esc untuk batal",
+	"attach.title":     "Pilih file untuk dikirim",
+	"attach.uploading": "Mengunggah %s...",
+
+	"chat.no_unread": "Tidak ada pesan baru",
+	"chat.unread":    "%d pesan belum dibaca",
+
+	"composer.editing":      "✎ Mengedit pesan · Esc untuk batal",
+	"composer.hint_edit":    " · E untuk mengedit",
+	"composer.hint_idle":    "i untuk mengetik · r untuk membalas · j/k untuk menggulir · / untuk mencari · Esc untuk menutup",
+	"composer.hint_info":    " · I untuk info pesan · Ctrl+G untuk info grup",
+	"composer.hint_media":   " · o untuk membuka · s untuk menyimpan",
+	"composer.hint_mention": "Tab/Enter untuk memilih · ↑/↓ untuk berpindah · Esc untuk menutup",
+	"composer.hint_react":   " · e untuk bereaksi",
+	"composer.hint_revoke":  " · D untuk menghapus",
+	"composer.hint_typing":  "Enter untuk mengirim · Ctrl+O untuk melampirkan · Esc untuk ke riwayat",
+	"composer.placeholder":  "Ketik pesan...",
+	"composer.replying":     "↪ Membalas %s · Esc untuk batal",
+
+	"conn.banned":          "Diblokir sementara",
+	"conn.banned_until":    "Diblokir sementara hingga %s",
+	"conn.connecting":      "Menyambung…",
+	"conn.failure":         "koneksi ditolak (%d) %s",
+	"conn.hint_reconnect":  "Ctrl+R untuk menyambung ulang",
+	"conn.offline":         "Offline",
+	"conn.online":          "Online",
+	"conn.queued":          "%d pesan antre",
+	"conn.reconnecting":    "Menyambung ulang (percobaan %d)…",
+	"conn.reconnecting_in": "Menyambung ulang (percobaan %d) dalam %v",
+	"conn.replaced":        "sesi dibuka di tempat lain",
+	"conn.stalled":         "Koneksi tersendat…",
+
+	"err.account_not_found":  "akun %s tidak ditemukan",
+	"err.attach":             "gagal melampirkan file",
+	"err.change_denied":      "mengabaikan perubahan pesan %s di %s dari pengirim lain",
+	"err.chat_state":         "gagal mengubah status chat %s: %v",
+	"err.client_not_ready":   "client belum siap",
+	"err.client_unavailable": "client tidak tersedia",
+	"err.clear_chat_data":    "gagal menghapus data chat",
+	"err.connect":            "gagal connect",
+	"err.edit":               "gagal mengedit pesan di %s: %v",
+	"err.group_info":         "gagal memuat info grup %s: %v",
+	"err.invalid_jid":        "jid tidak valid",
+	"err.load_chats":         "gagal memuat chat",
+	"err.load_contacts":      "gagal memuat kontak",
+	"err.load_messages":      "gagal memuat pesan",
+	"err.load_older":         "gagal memuat pesan lama",
+	"err.load_receipts":      "gagal memuat info pesan",
+	"err.logout":             "gagal logout",
+	"err.mark_read":          "gagal menandai %s sebagai dibaca: %v",
+	"err.media_download":     "gagal mengunduh lampiran",
+	"err.media_missing":      "pesan tidak ada di penyimpanan",
+	"err.media_open":         "gagal membuka lampiran",
+	"err.media_save":         "gagal menyimpan lampiran",
+	"err.notify":             "gagal mengirim notifikasi: %v",
+	"err.open_chat_data":     "gagal membuka data chat",
+	"err.open_session":       "gagal membuka session",
+	"err.pair_code":          "gagal membuat kode pairing",
+	"err.pin_limit":          "maksimal %d chat yang bisa disematkan",
+	"err.presence":           "gagal mengirim presence ke %s: %v",
+	"err.qr_channel":         "gagal membuat qr channel",
+	"err.react":              "gagal mengirim reaksi ke %s: %v",
+	"err.read_device":        "gagal membaca device",
+	"err.reconnect":          "gagal menyambung ulang: %v",
+	"err.request_history":    "gagal meminta history %s: %v",
+	"err.revoke":             "gagal menghapus pesan di %s: %v",
+	"err.save_chat":          "gagal menyimpan chat",
+	"err.save_history":       "gagal menyimpan history",
+	"err.save_message":       "gagal menyimpan pesan",
+	"err.save_reaction":      "gagal menyimpan reaksi",
+	"err.search":             "gagal mencari pesan",
+	"err.send":               "gagal mengirim ke %s: %v",
+	"err.session_missing":    "session store tidak tersedia",
+	"err.sync_contacts":      "gagal sync kontak",
+	"err.unknown":            "Terjadi kesalahan yang tidak diketahui.",
+	"err.upload":             "gagal mengunggah lampiran",
+
+	"error.keys": "r untuk coba lagi · q untuk keluar",
+
+	"group.admins":       "Admin",
+	"group.created":      "Dibuat %s",
+	"group.created_by":   "Dibuat %s oleh %s",
+	"group.loading":      "Memuat info grup...",
+	"group.members":      "%d anggota",
+	"group.more":         "… dan %d lainnya",
+	"group.no_topic":     "Tidak ada deskripsi",
+	"group.participants": "Anggota",
+
+	"hint.session_corrupt":  "Database rusak. Pindahkan file session ke tempat lain lalu pairing ulang.",
+	"hint.session_default":  "Periksa direktori data dan file log untuk detailnya.",
+	"hint.session_locked":   "Database sedang dipakai proses lain. Tutup watui lain yang masih berjalan, lalu coba lagi.",
+	"hint.session_readonly": "Database tidak bisa ditulis. Periksa izin direktori data atau gunakan --data-dir.",
+	"hint.session_too_new":  "Database dibuat oleh versi watui yang lebih baru. Perbarui watui terlebih dahulu.",
+	"hint.session_upgrade":  "Migrasi database gagal. Simpan cadangan file session lalu coba lagi.",
+
+	"info.delivered_to": "Terkirim ke",
+	"info.hint":         "Esc untuk menutup",
+	"info.none":         "Belum ada tanda terima dari anggota grup.",
+	"info.read_by":      "Dibaca oleh",
+	"info.title":        "Info pesan",
+
+	"logout.confirm_desc":   "Perangkat ini akan dilepas dari akun WhatsApp kamu.",
+	"logout.confirm_keys":   "y untuk logout · n/Esc untuk batal",
+	"logout.confirm_title":  "Logout dari akun ini?",
+	"logout.data_cleared":   "Riwayat chat lokal akun ini sudah dihapus.",
+	"logout.data_kept":      "Riwayat chat lokal masih tersimpan di perangkat ini.",
+	"logout.keys":           "Enter untuk pairing ulang · a untuk akun · q untuk keluar",
+	"logout.keys_clear":     "Enter untuk pairing ulang · d untuk menghapus riwayat chat lokal · a untuk akun · q untuk keluar",
+	"logout.reason_banned":  "Akun ini diblokir oleh WhatsApp.",
+	"logout.reason_locked":  "Akun ini dikunci oleh WhatsApp.",
+	"logout.reason_manual":  "Kamu sudah logout dari perangkat ini.",
+	"logout.reason_other":   "WhatsApp mengakhiri sesi ini (%v).",
+	"logout.reason_removed": "Perangkat ini dikeluarkan dari daftar perangkat tertaut di ponsel.",
+	"logout.title":          "Sesi berakhir",
+
+	"media.downloading": "Mengunduh lampiran...",
+	"media.none":        "Pesan ini tidak memiliki lampiran",
+	"media.opened":      "Membuka %s",
+	"media.save_hint":   "Enter untuk menyimpan · Esc untuk batal",
+	"media.save_prompt": "Simpan ke: ",
+	"media.saved":       "Disimpan ke %s",
+
+	"messages.deleted": "Pesan ini telah dihapus",
+	"messages.edited":  "diedit",
+	"messages.empty":   "Belum ada riwayat pesan.",
+	"messages.failed":  "gagal terkirim",
+	"messages.loading": "Memuat pesan lama...",
+	"messages.start":   "Awal percakapan",
+
+	"pair.code_steps": "Perangkat tertaut › Tautkan perangkat › Tautkan dengan nomor telepon",
+	"pair.enter_code": "Masukkan kode ini di WhatsApp",
+	"pair.scan_qr":    "Scan kode di bawah menggunakan WhatsApp",
+
+	"phone.hint":    "Enter untuk meminta kode · Esc untuk kembali",
+	"phone.invalid": "Nomor harus berupa angka dengan kode negara, misalnya 628123456789",
+	"phone.prompt":  "Masukkan nomor WhatsApp dengan kode negara:",
+	"phone.title":   "Pairing dengan nomor telepon",
+
+	"presence.last_seen": "terakhir dilihat %s",
+	"presence.many":      "%d orang %s",
+	"presence.online":    "online",
+	"presence.recording": "merekam audio…",
+	"presence.today":     "hari ini %s",
+	"presence.typing":    "mengetik…",
+
+	"qr.failed":      "Pairing gagal: %v",
+	"qr.multidevice": "Aktifkan multi-device di HP terlebih dahulu",
+	"qr.outdated":    "Client kedaluwarsa, update whatsmeow",
+	"qr.status":      "Status pairing: %s",
+	"qr.success":     "Pairing berhasil, menunggu sinkronisasi",
+	"qr.timeout":     "Sesi pairing habis, coba ulangi",
+	"qr.unexpected":  "Status pairing tidak terduga, coba ulangi",
+
+	"quit.confirm_desc":  "%d pesan yang antre saat offline belum terkirim dan akan hilang.",
+	"quit.confirm_keys":  "y untuk keluar · n/Esc untuk batal",
+	"quit.confirm_title": "Keluar dari watui?",
+
+	"react.hint":  "1–6 atau ←/→ dan Enter untuk memilih · x untuk menghapus · Esc untuk batal",
+	"react.title": "Reaksi untuk %s",
+
+	"revoke.confirm": "Hapus untuk semua orang: %s?",
+	"revoke.keys":    "y untuk menghapus · n/Esc untuk batal",
+
+	"rooms.archived":           "📦 Diarsipkan (%d)",
+	"rooms.archived_back":      "Esc untuk kembali",
+	"rooms.archived_empty":     "Tidak ada chat yang diarsipkan",
+	"rooms.archived_open":      "A untuk membuka",
+	"rooms.archived_title":     "📦 Diarsipkan",
+	"rooms.filter_placeholder": "Saring chat...",
+	"rooms.hint":               "Enter buka · / saring · p sematkan · m bisukan · a arsipkan · A arsip",
+	"rooms.no_match":           "Tidak ada chat yang cocok",
+
+	"search.empty":       "Tidak ada pesan yang cocok",
+	"search.hint":        "↑/↓ untuk memilih · Enter untuk membuka · Esc untuk menutup",
+	"search.placeholder": "Ketik kata kunci...",
+	"search.prompt":      "Ketik untuk mencari di semua chat",
+	"search.title":       "Cari pesan",
+
+	"sender.last":    "Terakhir",
+	"sender.me":      "Saya",
+	"sender.unknown": "Unknown",
+
+	"status.connecting":      "Menghubungkan...",
+	"status.connecting_wa":   "Menghubungkan ke WhatsApp...",
+	"status.logging_out":     "Keluar dari akun...",
+	"status.preparing":       "Menyiapkan...",
+	"status.preparing_wa":    "Menyiapkan WhatsApp session...",
+	"status.press_enter":     "Tekan Enter untuk mulai pairing",
+	"status.requesting_code": "Meminta kode pairing...",
+	"status.retrying":        "Mencoba lagi...",
+
+	"summary.audio":           "🎵 Audio",
+	"summary.document":        "📄 %s",
+	"summary.ephemeral_days":  "⏱ Pesan sementara diaktifkan: %d hari",
+	"summary.ephemeral_hours": "⏱ Pesan sementara diaktifkan: %d jam",
+	"summary.ephemeral_off":   "⏱ Pesan sementara dinonaktifkan",
+	"summary.live_location":   "📍 Lokasi realtime",
+	"summary.location":        "📍 Lokasi %.3f, %.3f",
+	"summary.new":             "Pesan baru",
+	"summary.photo":           "📷 Foto",
+	"summary.sticker":         "💠 Stiker",
+	"summary.type":            "Pesan %s",
+	"summary.video":           "🎥 Video",
+
+	"sync.continued":    "Sinkronisasi lanjutan · %d%%",
+	"sync.data":         "Sinkronisasi data",
+	"sync.default_kind": "history",
+	"sync.info":         "Sinkronisasi %s · %d chat",
+	"sync.resuming":     "Melanjutkan sinkronisasi · %d%%",
+	"sync.title":        "Sinkronisasi riwayat",
+	"sync.waiting":      "Menunggu data WhatsApp...",
+	"sync.waiting_wa":   "Menunggu history dari WhatsApp...",
+
+	"welcome.alt_hint": "p untuk pairing dengan nomor telepon · Ctrl+A untuk ganti akun",
+	"welcome.button":   " Continue ",
+	"welcome.desc":     "Connect WhatsAppmu langsung dari terminal tanpa ribet.",
+	"welcome.hint":     "Tekan Enter untuk pairing dengan QR.",
+}
@@ -0,0 +1,222 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/messageview"
+	"github.com/9d4/watui/roomlist"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// StoreOpener opens the chat store that belongs to a paired account.
+type StoreOpener func(account types.JID) (*chatstore.Store, error)
+
+type account struct {
+	jid  types.JID
+	name string
+}
+
+type accountsLoadedMsg struct {
+	accounts []account
+}
+
+func (m model) loadAccounts() tea.Cmd {
+	return func() tea.Msg {
+		devices, err := m.wa.Devices(context.Background())
+		if err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.read_device"), err)}
+		}
+
+		accounts := make([]account, 0, len(devices))
+		for _, d := range devices {
+			if d.ID == nil {
+				continue
+			}
+			accounts = append(accounts, account{jid: *d.ID, name: strings.TrimSpace(d.PushName)})
+		}
+		return accountsLoadedMsg{accounts: accounts}
+	}
+}
+
+func (m model) selectAccount(jid types.JID) tea.Cmd {
+	return func() tea.Msg {
+		d, err := m.wa.Device(context.Background(), jid)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.read_device"), err)}
+		}
+		if d == nil {
+			return errMsg{err: errors.New(i18n.T("err.account_not_found", jid))}
+		}
+
+		store, err := m.openStore(jid)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.open_chat_data"), err)}
+		}
+
+		return clientReadyMsg{cli: whatsmeow.NewClient(d, m.wa.WaLog()), store: store}
+	}
+}
+
+func (m model) addAccount() tea.Cmd {
+	return func() tea.Msg {
+		return clientReadyMsg{cli: whatsmeow.NewClient(m.wa.NewDevice(), m.wa.WaLog())}
+	}
+}
+
+func (m model) openStore(jid types.JID) (*chatstore.Store, error) {
+	if m.storeOpener == nil {
+		return nil, nil
+	}
+	return m.storeOpener(jid.ToNonAD())
+}
+
+func (m *model) applyAccounts(accounts []account) tea.Cmd {
+	m.accounts = accounts
+	if m.accountCursor > len(m.accounts) {
+		m.accountCursor = len(m.accounts)
+	}
+
+	if m.cli != nil {
+		m.state = stateAccounts
+		return nil
+	}
+
+	switch len(accounts) {
+	case 0:
+		return m.addAccount()
+	case 1:
+		return m.selectAccount(accounts[0].jid)
+	default:
+		m.state = stateAccounts
+		return nil
+	}
+}
+
+func (m model) activeAccount() *types.JID {
+	if m.cli == nil || m.cli.Store == nil || m.cli.Store.ID == nil {
+		return nil
+	}
+	jid := m.cli.Store.ID.ToNonAD()
+	return &jid
+}
+
+// switchClient detaches the current client and store and clears everything
+// that belongs to the previous account.
+func (m *model) switchClient() {
+	if m.cli != nil {
+		m.cli.RemoveEventHandlers()
+		m.cli.Disconnect()
+	}
+	if m.store != nil {
+		_ = m.store.Close()
+	}
+
+	m.cli = nil
+	m.store = nil
+	m.roomList = roomlist.New().SetViewportHeight(m.contentHeight())
+	m.messages = newMessageView(m.imagePreview, m.terminal).SetSize(m.messagePaneSize())
+	m.composer.Reset()
+	m.composer.Blur()
+	m.waQRCode = ""
+	m.qrStatus = ""
+	m.historyReady = false
+	m.syncOverlay = syncOverlayState{}
+	m.chatTitles = make(map[string]string)
+	m.chatMessages = make(map[string][]messageview.Message)
+	m.contactNames = make(map[string]string)
+	m.groups = make(map[string]*types.GroupInfo)
+	m.reactions = make(map[string]map[string]messageReactions)
+	m.search = searchState{input: newSearchInput()}
+	m.messageInfo = messageInfoState{}
+	m.reply = replyState{}
+	m.mention = mentionState{}
+	m.media = mediaState{input: newSavePathInput()}
+	m.attach = attachState{picker: newFilePicker()}
+	m.upload = uploadState{bar: m.upload.bar}
+	m.conn = connectionState{gen: m.conn.gen + 1}
+	m.logout = logoutState{}
+	m.presence = presenceState{}
+	m.typing = typingState{}
+	m.react = reactionState{}
+	m.edit = editState{}
+	m.revoke = revokeState{}
+}
+
+func (m model) updateAccounts(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "j", "down":
+		if m.accountCursor < len(m.accounts) {
+			m.accountCursor++
+		}
+
+	case "k", "up":
+		if m.accountCursor > 0 {
+			m.accountCursor--
+		}
+
+	case "n":
+		return m, m.addAccount()
+
+	case "enter":
+		if m.accountCursor == len(m.accounts) {
+			return m, m.addAccount()
+		}
+
+		selected := m.accounts[m.accountCursor].jid
+		if current := m.activeAccount(); current != nil && *current == selected.ToNonAD() {
+			m.state = stateChats
+			return m, nil
+		}
+		return m, m.selectAccount(selected)
+
+	case "esc":
+		if m.activeAccount() != nil {
+			m.state = stateChats
+		}
+
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m model) accountsView() string {
+	sections := []string{titleStyle.Render(i18n.T("accounts.title")), ""}
+
+	current := m.activeAccount()
+	for i, acc := range m.accounts {
+		label := acc.jid.ToNonAD().String()
+		if acc.name != "" {
+			label = fmt.Sprintf("%s (%s)", acc.name, acc.jid.User)
+		}
+		if current != nil && *current == acc.jid.ToNonAD() {
+			label += i18n.T("accounts.active")
+		}
+		sections = append(sections, accountItem(label, i == m.accountCursor))
+	}
+	sections = append(sections, accountItem(i18n.T("accounts.add"), m.accountCursor == len(m.accounts)))
+
+	hint := i18n.T("accounts.hint")
+	if current != nil {
+		hint += i18n.T("accounts.hint_back")
+	}
+	sections = append(sections, "", subtleStyle.Render(hint))
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+func accountItem(label string, selected bool) string {
+	if selected {
+		return lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")).Render("› " + label)
+	}
+	return subtleStyle.Render("  " + label)
+}
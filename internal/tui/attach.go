@@ -0,0 +1,302 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/media"
+	"github.com/9d4/watui/messageview"
+	"github.com/9d4/watui/roomlist"
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mau.fi/whatsmeow"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// attachCommand typed into the composer attaches the file at the given
+// path, or opens the file picker without one.
+const attachCommand = ":attach"
+
+// attachState holds the file picker and the file staged to go out with the
+// next message.
+type attachState struct {
+	picking bool
+	picker  filepicker.Model
+	staged  *media.File
+	chat    string
+}
+
+// uploadState is the attachment being uploaded, shown as a progress bar
+// above the composer.
+type uploadState struct {
+	active bool
+	id     types.MessageID
+	name   string
+	bar    progress.Model
+}
+
+type uploadProgressMsg struct {
+	id      types.MessageID
+	percent float64
+}
+
+func newFilePicker() filepicker.Model {
+	fp := filepicker.New()
+	if dir, err := os.Getwd(); err == nil {
+		fp.CurrentDirectory = dir
+	}
+	// Esc closes the picker instead of going up a directory.
+	fp.KeyMap.Back = key.NewBinding(key.WithKeys("h", "backspace", "left"))
+	return fp
+}
+
+func (m *model) openFilePicker() tea.Cmd {
+	if m.roomList.OpenedRoom() == nil {
+		return nil
+	}
+
+	m.attach.picking = true
+	m.attach.picker.SetHeight(max(1, m.contentHeight()-6))
+	m.composer.Blur()
+	return m.attach.picker.Init()
+}
+
+func (m *model) closeFilePicker() {
+	m.attach.picking = false
+}
+
+func (m model) updateFilePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.closeFilePicker()
+		return m, m.composer.Focus()
+	}
+
+	var cmd tea.Cmd
+	m.attach.picker, cmd = m.attach.picker.Update(msg)
+	if ok, path := m.attach.picker.DidSelectFile(msg); ok {
+		m.closeFilePicker()
+		return m, m.stageAttachment(path)
+	}
+	return m, cmd
+}
+
+// stageAttachment checks the file and keeps it for the next send; whatever
+// is typed in the composer goes out as its caption.
+func (m *model) stageAttachment(path string) tea.Cmd {
+	room := m.roomList.OpenedRoom()
+	if room == nil {
+		return nil
+	}
+
+	file, err := media.Inspect(expandPath(path))
+	if err != nil {
+		m.media.notice = "⚠ " + fmt.Errorf("%s: %w", i18n.T("err.attach"), err).Error()
+		return m.composer.Focus()
+	}
+
+	m.attach.staged = &file
+	m.attach.chat = room.ID
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+	return m.composer.Focus()
+}
+
+func (m *model) cancelAttachment() {
+	m.attach.staged = nil
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+}
+
+// stagedAttachment returns the file staged for the open room.
+func (m model) stagedAttachment() *media.File {
+	room := m.roomList.OpenedRoom()
+	if m.attach.staged == nil || room == nil || room.ID != m.attach.chat {
+		return nil
+	}
+	return m.attach.staged
+}
+
+// submitAttachment sends the staged file with caption. Audio cannot carry a
+// caption, so one typed for it is sent as a text message once the file is
+// sent.
+func (m *model) submitAttachment(room roomlist.Room, jid types.JID, file media.File, caption string) tea.Cmd {
+	target := m.replyTarget()
+	info := m.replyContext(room.ID, target, nil)
+
+	textAfter := ""
+	if file.Kind == "audio" {
+		textAfter, caption = caption, ""
+	}
+	body := attachmentSummary(file, caption)
+
+	m.composer.Reset()
+	m.resetDraft()
+
+	id := m.cli.GenerateMessageID()
+	ts := time.Now()
+	pending := messageview.Message{
+		ID:      string(id),
+		Sender:  i18n.T("sender.me"),
+		Body:    body,
+		Time:    ts,
+		FromMe:  true,
+		Pending: true,
+		Media:   true,
+	}
+	if target != nil {
+		pending.Quote = &messageview.Quote{ID: target.ID, Sender: target.Sender, SenderJID: target.SenderJID, Body: target.Body}
+	}
+	m.appendChatMessage(room.ID, pending)
+
+	updated := room
+	updated.LastMessage = body
+	updated.Time = ts
+	updated.Pending = true
+	m.roomList = m.roomList.UpsertRoom(updated)
+
+	m.upload = uploadState{active: true, id: id, name: file.Name, bar: m.upload.bar}
+	send := m.sendAttachment(jid, id, file, caption, body, info, ts)
+	if textAfter != "" {
+		send = tea.Sequence(send, m.queueText(room, jid, textAfter, nil))
+	}
+	return tea.Batch(m.upload.bar.SetPercent(0), m.dispatchSend(send))
+}
+
+func (m model) sendAttachment(jid types.JID, id types.MessageID, file media.File, caption, body string, info *waE2E.ContextInfo, ts time.Time) tea.Cmd {
+	cli, events := m.cli, m.events
+	return func() tea.Msg {
+		chat := jid.String()
+
+		last := 0.0
+		report := func(sent, total int64) {
+			if total <= 0 {
+				return
+			}
+			percent := float64(sent) / float64(total)
+			if percent-last < 0.01 && percent < 1 {
+				return
+			}
+			last = percent
+			events <- uploadProgressMsg{id: id, percent: percent}
+		}
+
+		content, err := media.Upload(context.Background(), cli, file, caption, report)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", i18n.T("err.upload"), err)
+			return messageSentMsg{chat: chat, id: id, body: body, ts: ts, err: err}
+		}
+
+		m.quoteOriginal(chat, info)
+		setContextInfo(content, info)
+
+		resp, err := cli.SendMessage(context.Background(), jid, content, whatsmeow.SendRequestExtra{ID: id})
+		if err == nil && !resp.Timestamp.IsZero() {
+			ts = resp.Timestamp
+		}
+		return messageSentMsg{chat: chat, id: id, body: body, content: content, ts: ts, err: err}
+	}
+}
+
+func (m *model) applyUploadProgress(msg uploadProgressMsg) tea.Cmd {
+	if !m.upload.active || m.upload.id != msg.id {
+		return nil
+	}
+	return m.upload.bar.SetPercent(msg.percent)
+}
+
+// finishUpload hides the progress bar once the send of id resolved.
+func (m *model) finishUpload(id types.MessageID) {
+	if m.upload.active && m.upload.id == id {
+		m.upload.active = false
+	}
+}
+
+func setContextInfo(msg *waE2E.Message, info *waE2E.ContextInfo) {
+	if info == nil {
+		return
+	}
+
+	switch {
+	case msg.GetImageMessage() != nil:
+		msg.ImageMessage.ContextInfo = info
+	case msg.GetAudioMessage() != nil:
+		msg.AudioMessage.ContextInfo = info
+	case msg.GetDocumentMessage() != nil:
+		msg.DocumentMessage.ContextInfo = info
+	}
+}
+
+// attachmentSummary matches what summarizeMessage shows for the message
+// once it is sent.
+func attachmentSummary(file media.File, caption string) string {
+	switch file.Kind {
+	case "image":
+		return withCaption(i18n.T("summary.photo"), caption)
+	case "audio":
+		return i18n.T("summary.audio")
+	default:
+		return withCaption(i18n.T("summary.document", file.Name), caption)
+	}
+}
+
+func (m model) filePickerView(width int) string {
+	sections := []string{
+		titleStyle.Render(i18n.T("attach.title")),
+		subtleStyle.Render(previewLine(m.attach.picker.CurrentDirectory, width-4)),
+		"",
+		m.attach.picker.View(),
+		"",
+		subtleStyle.Render(i18n.T("attach.hint")),
+	}
+	return lipgloss.NewStyle().Padding(0, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+// attachmentView is the line above the composer for a staged file or a
+// running upload.
+func (m model) attachmentView(width int) string {
+	if m.upload.active {
+		bar := m.upload.bar
+		bar.Width = max(10, min(40, width-4))
+		return subtleStyle.Render(previewLine(i18n.T("attach.uploading", m.upload.name), width-4)) + "\n" + bar.View()
+	}
+
+	file := m.stagedAttachment()
+	if file == nil {
+		return ""
+	}
+	label := i18n.T("attach.staged", file.Name, humanSize(file.Size))
+	return subtleStyle.Render(previewLine(label, width-4))
+}
+
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGT"[exp])
+}
+
+// parseAttachCommand reports whether body is the attach command and returns
+// its path argument.
+func parseAttachCommand(body string) (string, bool) {
+	if body != attachCommand && !strings.HasPrefix(body, attachCommand+" ") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(body, attachCommand)), true
+}
@@ -0,0 +1,65 @@
+package tui
+
+import "strings"
+
+// bigGlyphs draw the characters of pairing codes five rows tall.
+var bigGlyphs = map[rune][5]string{
+	'0': {"███", "█ █", "█ █", "█ █", "███"},
+	'1': {" █ ", "██ ", " █ ", " █ ", "███"},
+	'2': {"███", "  █", "███", "█  ", "███"},
+	'3': {"███", "  █", " ██", "  █", "███"},
+	'4': {"█ █", "█ █", "███", "  █", "  █"},
+	'5': {"███", "█  ", "███", "  █", "███"},
+	'6': {"███", "█  ", "███", "█ █", "███"},
+	'7': {"███", "  █", " █ ", " █ ", " █ "},
+	'8': {"███", "█ █", "███", "█ █", "███"},
+	'9': {"███", "█ █", "███", "  █", "███"},
+	'A': {" █ ", "█ █", "███", "█ █", "█ █"},
+	'B': {"██ ", "█ █", "██ ", "█ █", "██ "},
+	'C': {"███", "█  ", "█  ", "█  ", "███"},
+	'D': {"██ ", "█ █", "█ █", "█ █", "██ "},
+	'E': {"███", "█  ", "██ ", "█  ", "███"},
+	'F': {"███", "█  ", "██ ", "█  ", "█  "},
+	'G': {"███", "█  ", "█ █", "█ █", "███"},
+	'H': {"█ █", "█ █", "███", "█ █", "█ █"},
+	'I': {"███", " █ ", " █ ", " █ ", "███"},
+	'J': {"  █", "  █", "  █", "█ █", "███"},
+	'K': {"█ █", "█ █", "██ ", "█ █", "█ █"},
+	'L': {"█  ", "█  ", "█  ", "█  ", "███"},
+	'M': {"█ █", "███", "███", "█ █", "█ █"},
+	'N': {"██ ", "█ █", "█ █", "█ █", "█ █"},
+	'O': {" █ ", "█ █", "█ █", "█ █", " █ "},
+	'P': {"███", "█ █", "███", "█  ", "█  "},
+	'Q': {"███", "█ █", "█ █", "███", "  █"},
+	'R': {"██ ", "█ █", "██ ", "█ █", "█ █"},
+	'S': {" ██", "█  ", " █ ", "  █", "██ "},
+	'T': {"███", " █ ", " █ ", " █ ", " █ "},
+	'U': {"█ █", "█ █", "█ █", "█ █", "███"},
+	'V': {"█ █", "█ █", "█ █", "█ █", " █ "},
+	'W': {"█ █", "█ █", "███", "███", "█ █"},
+	'X': {"█ █", "█ █", " █ ", "█ █", "█ █"},
+	'Y': {"█ █", "█ █", " █ ", " █ ", " █ "},
+	'Z': {"███", "  █", " █ ", "█  ", "███"},
+	'-': {"   ", "   ", "███", "   ", "   "},
+}
+
+// bigText renders text with bigGlyphs. It returns "" when text has a
+// character without a glyph, and the caller shows it plainly instead.
+func bigText(text string) string {
+	var rows [5][]string
+	for _, r := range strings.ToUpper(text) {
+		glyph, ok := bigGlyphs[r]
+		if !ok {
+			return ""
+		}
+		for i, line := range glyph {
+			rows[i] = append(rows[i], line)
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = strings.Join(row, " ")
+	}
+	return strings.Join(lines, "\n")
+}
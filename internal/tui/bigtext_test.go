@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+// pairingAlphabet holds the characters WhatsApp uses in pairing codes.
+const pairingAlphabet = "123456789ABCDEFGHJKLMNPQRSTVWXYZ"
+
+func TestBigGlyphsCoverPairingCodes(t *testing.T) {
+	for _, r := range pairingAlphabet {
+		if _, ok := bigGlyphs[r]; !ok {
+			t.Errorf("no glyph for %q", r)
+		}
+	}
+}
+
+func TestBigGlyphsDistinct(t *testing.T) {
+	seen := make(map[[5]string]rune, len(bigGlyphs))
+	for r, glyph := range bigGlyphs {
+		if other, ok := seen[glyph]; ok {
+			t.Errorf("%q and %q share a glyph:\n%s", r, other, strings.Join(glyph[:], "\n"))
+		}
+		seen[glyph] = r
+	}
+}
+
+func TestBigText(t *testing.T) {
+	if got := bigText("ab-1"); len(strings.Split(got, "\n")) != 5 {
+		t.Errorf("bigText(%q) = %q, want five rows", "ab-1", got)
+	}
+	if got := bigText("A?"); got != "" {
+		t.Errorf("bigText(%q) = %q, want empty", "A?", got)
+	}
+}
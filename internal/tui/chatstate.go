@@ -0,0 +1,204 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/roomlist"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mau.fi/whatsmeow/appstate"
+	waHistorySync "go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// WhatsApp keeps at most this many chats pinned.
+const maxPinned = 3
+
+// chatStateSentMsg reports a pin, mute or archive patch we sent. previous
+// holds the state to put back when it failed.
+type chatStateSentMsg struct {
+	previous roomlist.Room
+	err      error
+}
+
+// applyChatStateEvent follows pin, mute and archive changes synced from
+// other devices. It returns false for other events.
+func (m *model) applyChatStateEvent(evt any) (tea.Cmd, bool) {
+	switch evt := evt.(type) {
+	case *events.Pin:
+		return m.changeChatState(evt.JID, func(room *roomlist.Room) {
+			room.Pinned = evt.Action.GetPinned()
+		}), true
+
+	case *events.Mute:
+		return m.changeChatState(evt.JID, func(room *roomlist.Room) {
+			room.MutedUntil = mutedUntil(evt.Action.GetMuted(), evt.Action.GetMuteEndTimestamp())
+		}), true
+
+	case *events.Archive:
+		return m.changeChatState(evt.JID, func(room *roomlist.Room) {
+			room.Archived = evt.Action.GetArchived()
+		}), true
+	}
+
+	return nil, false
+}
+
+// changeChatState applies fn to a known room. Chats we have no room for
+// yet take their state from history sync once they show up.
+func (m *model) changeChatState(jid types.JID, fn func(room *roomlist.Room)) tea.Cmd {
+	current := m.roomList.Room(jid.String())
+	if current == nil {
+		return nil
+	}
+
+	updated := *current
+	fn(&updated)
+	return m.setChatState(updated)
+}
+
+// setChatState shows the pinned, archived and muted state of room in the
+// list and stores it.
+func (m *model) setChatState(room roomlist.Room) tea.Cmd {
+	if room.Archived {
+		room.Pinned = false
+	}
+
+	m.roomList = m.roomList.SetMutedUntil(room.ID, room.MutedUntil)
+	m.roomList = m.roomList.SetArchived(room.ID, room.Archived)
+	m.roomList = m.roomList.SetPinned(room.ID, room.Pinned)
+
+	if m.store == nil {
+		return nil
+	}
+
+	chatStore := m.store
+	return func() tea.Msg {
+		if err := chatStore.SetChatState(context.Background(), room); err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_chat"), err)}
+		}
+		return nil
+	}
+}
+
+// keepChatState carries the state of the known room over to a rebuilt one.
+func keepChatState(room *roomlist.Room, current roomlist.Room) {
+	room.Pinned = current.Pinned
+	room.Archived = current.Archived
+	room.MutedUntil = current.MutedUntil
+}
+
+// mutedUntil reads a mute action, where a missing or negative end means
+// muted until unmuted.
+func mutedUntil(muted bool, endMS int64) time.Time {
+	switch {
+	case !muted:
+		return time.Time{}
+	case endMS <= 0:
+		return store.MutedForever
+	default:
+		return time.UnixMilli(endMS)
+	}
+}
+
+func conversationMutedUntil(conv *waHistorySync.Conversation) time.Time {
+	end := int64(conv.GetMuteEndTime())
+	switch {
+	case end == 0:
+		return time.Time{}
+	case end < 0:
+		return store.MutedForever
+	default:
+		return time.Unix(end, 0)
+	}
+}
+
+// togglePin pins or unpins the room under the cursor.
+func (m *model) togglePin() tea.Cmd {
+	room := m.roomList.CursorRoom()
+	if room == nil || room.Archived {
+		return nil
+	}
+	if !room.Pinned && m.pinnedCount() >= maxPinned {
+		m.pushDevLog(i18n.T("err.pin_limit", maxPinned))
+		return nil
+	}
+
+	updated := *room
+	updated.Pinned = !room.Pinned
+	return m.sendChatState(*room, updated, func(jid types.JID) appstate.PatchInfo {
+		return appstate.BuildPin(jid, updated.Pinned)
+	})
+}
+
+// toggleMute mutes the room under the cursor until it is unmuted, or
+// unmutes it.
+func (m *model) toggleMute() tea.Cmd {
+	room := m.roomList.CursorRoom()
+	if room == nil {
+		return nil
+	}
+
+	updated := *room
+	updated.MutedUntil = time.Time{}
+	if !room.Muted() {
+		updated.MutedUntil = store.MutedForever
+	}
+	return m.sendChatState(*room, updated, func(jid types.JID) appstate.PatchInfo {
+		return appstate.BuildMute(jid, !updated.MutedUntil.IsZero(), 0)
+	})
+}
+
+// toggleArchive moves the room under the cursor in or out of the archive.
+func (m *model) toggleArchive() tea.Cmd {
+	room := m.roomList.CursorRoom()
+	if room == nil {
+		return nil
+	}
+
+	updated := *room
+	updated.Archived = !room.Archived
+	return m.sendChatState(*room, updated, func(jid types.JID) appstate.PatchInfo {
+		return appstate.BuildArchive(jid, updated.Archived, room.Time, nil)
+	})
+}
+
+// sendChatState shows updated right away and sends the patch built for its
+// chat, queued while offline.
+func (m *model) sendChatState(previous, updated roomlist.Room, patch func(jid types.JID) appstate.PatchInfo) tea.Cmd {
+	jid, err := types.ParseJID(updated.ID)
+	if err != nil || m.cli == nil {
+		return nil
+	}
+
+	cli, info := m.cli, patch(jid)
+	return tea.Batch(m.setChatState(updated), m.dispatchSend(func() tea.Msg {
+		return chatStateSentMsg{previous: previous, err: cli.SendAppState(context.Background(), info)}
+	}))
+}
+
+func (m *model) applyChatStateSent(msg chatStateSentMsg) tea.Cmd {
+	if msg.err == nil {
+		return nil
+	}
+
+	m.pushDevLog(i18n.T("err.chat_state", msg.previous.ID, msg.err))
+	if m.roomList.Room(msg.previous.ID) == nil {
+		return nil
+	}
+	return m.setChatState(msg.previous)
+}
+
+func (m model) pinnedCount() int {
+	n := 0
+	for _, room := range m.roomList.Rooms {
+		if room.Pinned {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,272 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/messageview"
+	"github.com/9d4/watui/roomlist"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mau.fi/whatsmeow"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+type messageSentMsg struct {
+	chat    string
+	id      types.MessageID
+	body    string
+	content *waE2E.Message
+	ts      time.Time
+	err     error
+}
+
+func newComposer() textarea.Model {
+	ta := textarea.New()
+	ta.Placeholder = i18n.T("composer.placeholder")
+	ta.ShowLineNumbers = false
+	ta.Prompt = "› "
+	ta.CharLimit = 4096
+	ta.SetHeight(1)
+	ta.KeyMap.InsertNewline.SetEnabled(false)
+	return ta
+}
+
+func (m model) composing() bool {
+	return m.state == stateChats && m.composer.Focused() && m.roomList.OpenedRoom() != nil
+}
+
+func (m model) updateComposer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		switch {
+		case m.mention.suggesting():
+			m.mention.candidates = nil
+		case m.stagedAttachment() != nil:
+			m.cancelAttachment()
+		case m.edit.active:
+			m.cancelEdit()
+		case m.reply.active:
+			m.cancelReply()
+		default:
+			m.composer.Blur()
+			return m, m.stopTyping()
+		}
+		return m, nil
+
+	case "tab", "enter":
+		if m.mention.suggesting() {
+			m.acceptMention()
+			return m, nil
+		}
+		if msg.String() == "enter" {
+			return m, tea.Batch(m.submitComposer(), m.stopTyping())
+		}
+
+	case "ctrl+o":
+		if m.edit.active {
+			return m, nil
+		}
+		return m, m.openFilePicker()
+
+	case "up", "ctrl+p":
+		if m.mention.suggesting() {
+			m.moveMentionCursor(-1)
+			return m, nil
+		}
+
+	case "down", "ctrl+n":
+		if m.mention.suggesting() {
+			m.moveMentionCursor(1)
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.composer, cmd = m.composer.Update(msg)
+	m.updateMentionSuggestions()
+	return m, tea.Batch(cmd, m.updateTyping())
+}
+
+// resetDraft drops the reply target, mentions, attachment and edited
+// message of the composed message.
+func (m *model) resetDraft() {
+	m.reply = replyState{}
+	m.edit = editState{}
+	m.mention = mentionState{}
+	m.attach.staged = nil
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+}
+
+func (m *model) submitComposer() tea.Cmd {
+	body := strings.TrimSpace(m.composer.Value())
+	room := m.roomList.OpenedRoom()
+	if room == nil || m.cli == nil {
+		return nil
+	}
+	if m.edit.active {
+		return m.submitEdit(*room, body)
+	}
+
+	if path, ok := parseAttachCommand(body); ok {
+		m.composer.Reset()
+		if path == "" {
+			return m.openFilePicker()
+		}
+		return m.stageAttachment(path)
+	}
+
+	file := m.stagedAttachment()
+	if body == "" && file == nil {
+		return nil
+	}
+
+	jid, err := types.ParseJID(room.ID)
+	if err != nil {
+		return func() tea.Msg {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.invalid_jid"), err)}
+		}
+	}
+
+	if file != nil {
+		return m.submitAttachment(*room, jid, *file, body)
+	}
+
+	return m.dispatchSend(m.queueText(*room, jid, body, m.replyTarget()))
+}
+
+// queueText shows body as a pending message in room, replying to target
+// when set, and returns the command that sends it.
+func (m *model) queueText(room roomlist.Room, jid types.JID, body string, target *messageview.Message) tea.Cmd {
+	text, mentioned := m.wireMentions(body)
+	content := &waE2E.Message{Conversation: proto.String(text)}
+	if info := m.replyContext(room.ID, target, mentioned); info != nil {
+		content = &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text:        proto.String(text),
+			ContextInfo: info,
+		}}
+	}
+
+	pending := messageview.Message{
+		Sender:    i18n.T("sender.me"),
+		Body:      body,
+		FromMe:    true,
+		Pending:   true,
+		Mentioned: mentioned,
+	}
+	if target != nil {
+		pending.Quote = &messageview.Quote{
+			ID:        target.ID,
+			Sender:    target.Sender,
+			SenderJID: target.SenderJID,
+			Body:      target.Body,
+		}
+	}
+
+	m.composer.Reset()
+	m.resetDraft()
+
+	id := m.cli.GenerateMessageID()
+	ts := time.Now()
+	pending.ID = string(id)
+	pending.Time = ts
+	m.appendChatMessage(room.ID, pending)
+
+	updated := room
+	updated.LastMessage = body
+	updated.Time = ts
+	updated.Pending = true
+	m.roomList = m.roomList.UpsertRoom(updated)
+
+	return m.sendText(jid, id, body, content, ts)
+}
+
+// sendText sends content, whose text is body with mentions in wire form.
+func (m model) sendText(jid types.JID, id types.MessageID, body string, content *waE2E.Message, ts time.Time) tea.Cmd {
+	cli := m.cli
+	return func() tea.Msg {
+		m.quoteOriginal(jid.String(), content.GetExtendedTextMessage().GetContextInfo())
+
+		resp, err := cli.SendMessage(context.Background(), jid, content, whatsmeow.SendRequestExtra{ID: id})
+		if err == nil && !resp.Timestamp.IsZero() {
+			ts = resp.Timestamp
+		}
+		return messageSentMsg{chat: jid.String(), id: id, body: body, content: content, ts: ts, err: err}
+	}
+}
+
+// resolvePendingSend updates the pending message once the server
+// acknowledged (or rejected) it.
+func (m *model) resolvePendingSend(msg messageSentMsg) tea.Cmd {
+	m.finishUpload(msg.id)
+
+	// Receipts can beat the send response, so keep a status they raised.
+	status := messageview.StatusSent
+	m.updateChatMessage(msg.chat, string(msg.id), func(item *messageview.Message) {
+		item.Pending = false
+		item.Failed = msg.err != nil
+		if msg.err == nil {
+			item.Time = msg.ts
+			item.Status = max(item.Status, messageview.StatusSent)
+			status = item.Status
+		}
+	})
+
+	if msg.err != nil {
+		m.pushDevLog(i18n.T("err.send", msg.chat, msg.err))
+		m.updatePendingRoom(msg.chat, msg.body, func(room *roomlist.Room) {
+			room.LastMessage = "⚠ " + msg.body
+		})
+		return nil
+	}
+
+	cmds := []tea.Cmd{m.persistMessage(sentRecord(msg, storeStatus(status)))}
+	if room, ok := m.updatePendingRoom(msg.chat, msg.body, func(room *roomlist.Room) {
+		room.Time = msg.ts
+	}); ok {
+		cmds = append(cmds, m.persistRoom(room))
+	}
+	return tea.Batch(cmds...)
+}
+
+// updatePendingRoom clears the pending flag of a room whose preview still
+// shows body, applying fn before the room is upserted again.
+func (m *model) updatePendingRoom(jid, body string, fn func(room *roomlist.Room)) (roomlist.Room, bool) {
+	for _, room := range m.roomList.Rooms {
+		if room.ID != jid {
+			continue
+		}
+		if room.LastMessage != body || !room.Pending {
+			return room, false
+		}
+
+		room.Pending = false
+		fn(&room)
+		m.roomList = m.roomList.UpsertRoom(room)
+		return room, true
+	}
+
+	return roomlist.Room{}, false
+}
+
+func sentRecord(msg messageSentMsg, status chatstore.MessageStatus) chatstore.Message {
+	return chatstore.Message{
+		ID:        string(msg.id),
+		ChatJID:   msg.chat,
+		Timestamp: msg.ts,
+		FromMe:    true,
+		Type:      messageType(msg.content),
+		Body:      summarizeMessage(msg.content),
+		Raw:       marshalMessage(msg.content),
+		Status:    status,
+	}
+}
@@ -0,0 +1,273 @@
+package tui
+
+import (
+	"time"
+
+	"github.com/9d4/watui/i18n"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+type connStatus int
+
+const (
+	connConnecting connStatus = iota
+	connOnline
+	connStalled
+	connReconnecting
+	connOffline
+	connBanned
+)
+
+// whatsmeow waits this long per failed attempt before reconnecting again.
+const reconnectStep = 2 * time.Second
+
+// connectionState follows the websocket of the client. whatsmeow reconnects
+// by itself after drops; the TUI only reconnects when asked to, or when a
+// temporary ban expires.
+type connectionState struct {
+	status  connStatus
+	attempt int
+	retryAt time.Time
+	reason  string
+
+	// outbox holds sends composed while not online, in order.
+	outbox []tea.Cmd
+
+	// gen invalidates ticks scheduled for an earlier state.
+	gen int
+}
+
+type reconnectFailedMsg struct {
+	err     error
+	retryIn time.Duration
+}
+
+type reconnectResultMsg struct {
+	gen int
+	err error
+}
+
+type connTickMsg struct {
+	gen int
+}
+
+type banExpiredMsg struct {
+	gen int
+}
+
+// watchReconnects reports each failed automatic reconnect attempt, with the
+// delay before the next one.
+func (m model) watchReconnects(cli *whatsmeow.Client) {
+	events := m.events
+	cli.AutoReconnectHook = func(err error) bool {
+		events <- reconnectFailedMsg{err: err, retryIn: time.Duration(cli.AutoReconnectErrors) * reconnectStep}
+		return true
+	}
+}
+
+// applyConnectionEvent updates the status for connection events of the
+// client. It returns false for other events.
+func (m *model) applyConnectionEvent(evt any) (tea.Cmd, bool) {
+	switch evt := evt.(type) {
+	case *events.Connected:
+		return m.setOnline(), true
+
+	case *events.KeepAliveRestored:
+		if m.conn.status == connStalled {
+			return m.setOnline(), true
+		}
+		return nil, true
+
+	case *events.KeepAliveTimeout:
+		if m.conn.status == connOnline {
+			m.conn.status = connStalled
+		}
+		return nil, true
+
+	case *events.Disconnected:
+		if m.conn.status == connOffline || m.conn.status == connBanned {
+			return nil, true
+		}
+		// The first attempt follows right away.
+		return m.setReconnecting(1, 0, nil), true
+
+	case *events.TemporaryBan:
+		return m.setBanned(evt), true
+
+	case *events.ConnectFailure:
+		m.setOffline(i18n.T("conn.failure", int(evt.Reason), evt.Message))
+		return nil, true
+
+	case *events.StreamReplaced:
+		m.setOffline(i18n.T("conn.replaced"))
+		return nil, true
+
+	case *events.ClientOutdated, *events.CATRefreshError:
+		m.setOffline(evt.(events.PermanentDisconnect).PermanentDisconnectDescription())
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// setOnline marks the client connected and sends what queued up meanwhile.
+func (m *model) setOnline() tea.Cmd {
+	outbox := m.conn.outbox
+	m.conn = connectionState{status: connOnline, gen: m.conn.gen + 1}
+	if len(outbox) == 0 {
+		return nil
+	}
+	return tea.Sequence(outbox...)
+}
+
+func (m *model) setReconnecting(attempt, failures int, err error) tea.Cmd {
+	m.conn.gen++
+	m.conn.status = connReconnecting
+	m.conn.attempt = attempt
+	m.conn.retryAt = time.Now().Add(time.Duration(failures) * reconnectStep)
+	m.conn.reason = ""
+	if err != nil {
+		m.pushDevLog(i18n.T("err.reconnect", err))
+	}
+	return m.connTick()
+}
+
+func (m *model) setOffline(reason string) {
+	m.conn.gen++
+	m.conn.status = connOffline
+	m.conn.reason = reason
+	m.conn.retryAt = time.Time{}
+}
+
+// setBanned waits out a temporary ban, then connects again.
+func (m *model) setBanned(evt *events.TemporaryBan) tea.Cmd {
+	m.conn.gen++
+	m.conn.status = connBanned
+	m.conn.reason = evt.Code.String()
+	m.conn.retryAt = time.Time{}
+	if evt.Expire <= 0 {
+		return nil
+	}
+
+	m.conn.retryAt = time.Now().Add(evt.Expire)
+	gen := m.conn.gen
+	return tea.Tick(evt.Expire, func(time.Time) tea.Msg {
+		return banExpiredMsg{gen: gen}
+	})
+}
+
+func (m *model) applyReconnectFailed(msg reconnectFailedMsg) tea.Cmd {
+	if m.conn.status == connOffline || m.conn.status == connBanned {
+		return nil
+	}
+	return m.setReconnecting(m.conn.attempt+1, int(msg.retryIn/reconnectStep), msg.err)
+}
+
+// reconnect connects right away, for the user or once a ban expired. While
+// whatsmeow is reconnecting by itself it is left alone.
+func (m *model) reconnect() tea.Cmd {
+	if m.cli == nil || (m.conn.status != connOffline && m.conn.status != connBanned) {
+		return nil
+	}
+
+	m.conn.gen++
+	m.conn.status = connConnecting
+	m.conn.retryAt = time.Time{}
+
+	cli, gen := m.cli, m.conn.gen
+	return func() tea.Msg {
+		if cli.IsConnected() {
+			return nil
+		}
+		return reconnectResultMsg{gen: gen, err: cli.Connect()}
+	}
+}
+
+func (m *model) applyBanExpired(msg banExpiredMsg) tea.Cmd {
+	if msg.gen != m.conn.gen {
+		return nil
+	}
+	return m.reconnect()
+}
+
+func (m *model) applyReconnectResult(msg reconnectResultMsg) {
+	if msg.gen != m.conn.gen || msg.err == nil {
+		return
+	}
+	m.setOffline(msg.err.Error())
+}
+
+// connTick redraws the countdown of the status line every second.
+func (m model) connTick() tea.Cmd {
+	gen := m.conn.gen
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return connTickMsg{gen: gen}
+	})
+}
+
+func (m model) applyConnTick(msg connTickMsg) tea.Cmd {
+	if msg.gen != m.conn.gen || m.conn.retryAt.IsZero() || time.Now().After(m.conn.retryAt) {
+		return nil
+	}
+	return m.connTick()
+}
+
+// dispatchSend runs send now when online, otherwise queues it for the next
+// connect.
+func (m *model) dispatchSend(send tea.Cmd) tea.Cmd {
+	if m.conn.status == connOnline {
+		return send
+	}
+	m.conn.outbox = append(m.conn.outbox, send)
+	return nil
+}
+
+var (
+	connOnlineStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	connWaitingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
+	connDownStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// connectionView is the status line above the room list.
+func (m model) connectionView(width int) string {
+	var line string
+	switch m.conn.status {
+	case connOnline:
+		line = connOnlineStyle.Render("● " + i18n.T("conn.online"))
+	case connConnecting:
+		line = connWaitingStyle.Render("◌ " + i18n.T("conn.connecting"))
+	case connStalled:
+		line = connWaitingStyle.Render("◌ " + i18n.T("conn.stalled"))
+	case connReconnecting:
+		label := i18n.T("conn.reconnecting", m.conn.attempt)
+		if wait := time.Until(m.conn.retryAt); wait > 0 {
+			label = i18n.T("conn.reconnecting_in", m.conn.attempt, wait.Round(time.Second))
+		}
+		line = connWaitingStyle.Render("◌ " + label)
+	case connOffline:
+		line = connDownStyle.Render("○ " + i18n.T("conn.offline"))
+	case connBanned:
+		label := i18n.T("conn.banned")
+		if !m.conn.retryAt.IsZero() {
+			label = i18n.T("conn.banned_until", m.conn.retryAt.Format("02 Jan 15:04"))
+		}
+		line = connDownStyle.Render("⛔ " + label)
+	}
+
+	if n := len(m.conn.outbox); n > 0 {
+		line += subtleStyle.Render(" · " + i18n.T("conn.queued", n))
+	}
+
+	if m.conn.status != connOffline && m.conn.status != connBanned {
+		return line
+	}
+
+	detail := i18n.T("conn.hint_reconnect")
+	if m.conn.reason != "" {
+		detail = m.conn.reason + " · " + detail
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, line, subtleStyle.MaxWidth(width).Render(detail))
+}
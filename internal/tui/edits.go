@@ -0,0 +1,482 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/messageview"
+	"github.com/9d4/watui/roomlist"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mau.fi/whatsmeow"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	waHistorySync "go.mau.fi/whatsmeow/proto/waHistorySync"
+	waWeb "go.mau.fi/whatsmeow/proto/waWeb"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// WhatsApp accepts a revoke from the sender for about two days.
+const revokeWindow = 48 * time.Hour
+
+// editState is our message the composer is rewriting.
+type editState struct {
+	active  bool
+	chat    string
+	message messageview.Message
+}
+
+// revokeState is our message waiting for the user to confirm deleting it
+// for everyone.
+type revokeState struct {
+	confirming bool
+	chat       string
+	message    messageview.Message
+}
+
+type editSentMsg struct {
+	chat     string
+	body     string
+	shown    string
+	at       time.Time
+	previous messageview.Message
+	err      error
+}
+
+type revokeSentMsg struct {
+	chat     string
+	previous messageview.Message
+	err      error
+}
+
+// messageChange is an edit or revoke synced along with history.
+type messageChange struct {
+	content *waE2E.Message
+	at      time.Time
+	revoked bool
+}
+
+// applyProtocolMessage applies edits and revokes to the message they
+// target. It returns false for protocol messages shown in the chat.
+func (m *model) applyProtocolMessage(evt *events.Message) (tea.Cmd, bool) {
+	protocol := evt.Message.GetProtocolMessage()
+	if protocol == nil {
+		return nil, false
+	}
+
+	chat := evt.Info.Chat.String()
+	id := protocol.GetKey().GetID()
+	switch {
+	case protocol.Type == nil:
+		return nil, true
+	case protocol.GetType() == waE2E.ProtocolMessage_MESSAGE_EDIT:
+		at := senderTime(protocol.GetTimestampMS(), evt.Info.Timestamp)
+		return m.applyEdit(chat, id, protocol.GetEditedMessage(), at, m.changeSenderOf(evt)), true
+	case protocol.GetType() == waE2E.ProtocolMessage_REVOKE:
+		return m.applyRevoke(chat, id, m.changeSenderOf(evt)), true
+	case protocol.GetType() == waE2E.ProtocolMessage_EPHEMERAL_SETTING:
+		// A changed disappearing timer is a notice in the chat, as on the
+		// phone.
+		return nil, false
+	default:
+		// Key shares, history sync notifications and the like are handled
+		// by whatsmeow.
+		return nil, true
+	}
+}
+
+// changeSender is who sent an edit or revoke. whatsmeow does not check it
+// against the author of the message changed, so we do.
+type changeSender struct {
+	chat  string
+	own   bool
+	jids  []string
+	admin bool
+}
+
+func (m model) changeSenderOf(evt *events.Message) changeSender {
+	sender := changeSender{chat: evt.Info.Chat.String(), own: evt.Info.IsFromMe}
+	for _, jid := range []types.JID{evt.Info.Sender, evt.Info.SenderAlt} {
+		if !jid.IsEmpty() {
+			sender.jids = append(sender.jids, jid.ToNonAD().String())
+		}
+	}
+
+	if group := m.groups[sender.chat]; group != nil {
+		for _, p := range group.Participants {
+			if !p.IsAdmin && !p.IsSuperAdmin {
+				continue
+			}
+			for _, jid := range []types.JID{p.JID, p.PhoneNumber, p.LID} {
+				if !jid.IsEmpty() && slices.Contains(sender.jids, jid.ToNonAD().String()) {
+					sender.admin = true
+				}
+			}
+		}
+	}
+	return sender
+}
+
+// may reports whether the sender may change a message by author, or by us
+// when fromMe. Only the author edits; group admins may also delete.
+func (s changeSender) may(fromMe bool, author string, revoke bool) bool {
+	if revoke && s.admin {
+		return true
+	}
+	if fromMe || s.own {
+		return fromMe && s.own
+	}
+	if author == "" && !isGroup(s.chat) {
+		author = s.chat
+	}
+	return author != "" && slices.Contains(s.jids, author)
+}
+
+// chatMessage finds a loaded message of chat.
+func (m model) chatMessage(chat, id string) (messageview.Message, bool) {
+	if m.messages.ChatID == chat {
+		if i := slices.IndexFunc(m.messages.Messages, func(msg messageview.Message) bool { return msg.ID == id }); i >= 0 {
+			return m.messages.Messages[i], true
+		}
+	}
+	messages := m.chatMessages[chat]
+	if i := slices.IndexFunc(messages, func(msg messageview.Message) bool { return msg.ID == id }); i >= 0 {
+		return messages[i], true
+	}
+	return messageview.Message{}, false
+}
+
+// allowChange drops edits and revokes of a loaded message its sender may
+// not make. Messages not loaded are checked against the store.
+func (m *model) allowChange(id string, sender changeSender, revoke bool) bool {
+	target, ok := m.chatMessage(sender.chat, id)
+	if !ok || sender.may(target.FromMe, target.SenderJID, revoke) {
+		return true
+	}
+	m.pushDevLog(i18n.T("err.change_denied", id, sender.chat))
+	return false
+}
+
+func (m *model) applyEdit(chat, id string, content *waE2E.Message, at time.Time, sender changeSender) tea.Cmd {
+	stored := summarizeMessage(content)
+	if id == "" || stored == "" || !m.allowChange(id, sender, false) {
+		return nil
+	}
+
+	mentioned := contextInfo(content).GetMentionedJID()
+	body := m.resolveMentions(stored, mentioned)
+	m.updateChatMessage(chat, id, func(msg *messageview.Message) {
+		if !msg.Deleted {
+			msg.Body = body
+			msg.Mentioned = mentioned
+			msg.Edited = true
+		}
+	})
+	return tea.Batch(m.persistEdit(chat, id, stored, at, sender), m.updateRoomPreview(chat, id, body))
+}
+
+func (m *model) applyRevoke(chat, id string, sender changeSender) tea.Cmd {
+	if id == "" || !m.allowChange(id, sender, true) {
+		return nil
+	}
+
+	m.updateChatMessage(chat, id, markDeleted)
+	delete(m.reactions[chat], id)
+	return tea.Batch(m.persistRevoke(chat, id, sender), m.updateRoomPreview(chat, id, i18n.T("messages.deleted")))
+}
+
+// markDeleted drops what a revoked message showed.
+func markDeleted(msg *messageview.Message) {
+	msg.Body = ""
+	msg.Deleted = true
+	msg.Edited = false
+	msg.Media = false
+	msg.Thumbnail = nil
+	msg.Quote = nil
+	msg.Mentioned = nil
+	msg.Reactions = nil
+}
+
+// updateRoomPreview rewrites the preview of chat when it shows the changed
+// message.
+func (m *model) updateRoomPreview(chat, id, preview string) tea.Cmd {
+	messages := m.chatMessages[chat]
+	if len(messages) == 0 || messages[len(messages)-1].ID != id {
+		return nil
+	}
+
+	room := m.roomList.Room(chat)
+	if room == nil {
+		return nil
+	}
+	room.LastMessage = preview
+	m.roomList = m.roomList.UpsertRoom(*room)
+	return m.persistRoom(*room)
+}
+
+func (m model) persistEdit(chat, id, body string, at time.Time, sender changeSender) tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+
+	store := m.store
+	return func() tea.Msg {
+		if !storedChangeAllowed(store, chat, id, sender, false) {
+			return nil
+		}
+		if err := store.EditMessage(context.Background(), chat, id, body, at); err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_message"), err)}
+		}
+		return nil
+	}
+}
+
+func (m model) persistRevoke(chat, id string, sender changeSender) tea.Cmd {
+	if m.store == nil {
+		return nil
+	}
+
+	store := m.store
+	return func() tea.Msg {
+		if !storedChangeAllowed(store, chat, id, sender, true) {
+			return nil
+		}
+		if err := store.RevokeMessage(context.Background(), chat, id); err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_message"), err)}
+		}
+		return nil
+	}
+}
+
+// storedChangeAllowed checks an edit or revoke against the stored author of
+// its message. Messages we do not have need no change.
+func storedChangeAllowed(store *chatstore.Store, chat, id string, sender changeSender, revoke bool) bool {
+	target, err := store.Message(context.Background(), chat, id)
+	if err != nil || target == nil {
+		return false
+	}
+	return sender.may(target.FromMe, target.SenderJID, revoke)
+}
+
+// conversationChanges collects the edits and revokes in a history
+// conversation by the ID of the message they change.
+func conversationChanges(conv *waHistorySync.Conversation) map[string]messageChange {
+	changes := make(map[string]messageChange)
+	for _, msg := range conv.GetMessages() {
+		info := msg.GetMessage()
+		if info == nil {
+			continue
+		}
+
+		if info.GetMessageStubType() == waWeb.WebMessageInfo_REVOKE {
+			changes[info.GetKey().GetID()] = messageChange{revoked: true}
+			continue
+		}
+
+		protocol := info.GetMessage().GetProtocolMessage()
+		if protocol == nil || protocol.Type == nil || protocol.GetKey().GetID() == "" {
+			continue
+		}
+
+		id := protocol.GetKey().GetID()
+		switch protocol.GetType() {
+		case waE2E.ProtocolMessage_REVOKE:
+			changes[id] = messageChange{revoked: true}
+		case waE2E.ProtocolMessage_MESSAGE_EDIT:
+			at := senderTime(protocol.GetTimestampMS(), time.Unix(int64(info.GetMessageTimestamp()), 0))
+			if current, ok := changes[id]; ok && (current.revoked || current.at.After(at)) {
+				continue
+			}
+			changes[id] = messageChange{content: protocol.GetEditedMessage(), at: at}
+		}
+	}
+	return changes
+}
+
+func (c messageChange) applyRecord(record *chatstore.Message) {
+	if c.revoked {
+		record.Type = chatstore.TypeRevoked
+		record.Body = ""
+		record.Raw = nil
+		return
+	}
+	if body := summarizeMessage(c.content); body != "" {
+		record.Body = body
+		record.EditedAt = c.at
+	}
+}
+
+func (m *model) applyView(c messageChange, view *messageview.Message) {
+	if c.revoked {
+		markDeleted(view)
+		return
+	}
+	if body := summarizeMessage(c.content); body != "" {
+		view.Mentioned = contextInfo(c.content).GetMentionedJID()
+		view.Body = m.resolveMentions(body, view.Mentioned)
+		view.Edited = true
+	}
+}
+
+// editable reports whether msg is ours and recent enough to edit. Only
+// text can be edited here.
+func editable(msg *messageview.Message) bool {
+	return ownChangeable(msg) && !msg.Media && time.Since(msg.Time) < whatsmeow.EditWindow
+}
+
+func revocable(msg *messageview.Message) bool {
+	return ownChangeable(msg) && time.Since(msg.Time) < revokeWindow
+}
+
+func ownChangeable(msg *messageview.Message) bool {
+	return msg != nil && msg.FromMe && msg.ID != "" && !msg.Pending && !msg.Failed && !msg.Deleted
+}
+
+func (m *model) startEdit() tea.Cmd {
+	selected := m.messages.Selected()
+	if !editable(selected) {
+		return nil
+	}
+
+	m.resetDraft()
+	m.edit = editState{active: true, chat: m.messages.ChatID, message: *selected}
+	m.restoreMentions(selected.Mentioned)
+	m.composer.SetValue(selected.Body)
+	m.composer.CursorEnd()
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+	return m.composer.Focus()
+}
+
+func (m *model) cancelEdit() {
+	m.edit = editState{}
+	m.composer.Reset()
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+}
+
+// submitEdit sends the composed text as the new body of the edited message
+// and shows it right away.
+func (m *model) submitEdit(room roomlist.Room, body string) tea.Cmd {
+	chat, target := m.edit.chat, m.edit.message
+	text, mentioned := m.wireMentions(body)
+	m.composer.Reset()
+	m.resetDraft()
+
+	if body == "" || body == target.Body || room.ID != chat {
+		return nil
+	}
+
+	jid, err := types.ParseJID(room.ID)
+	if err != nil {
+		return nil
+	}
+
+	content := &waE2E.Message{Conversation: proto.String(text)}
+	if info := m.replyContext(room.ID, nil, mentioned); info != nil {
+		content = &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text:        proto.String(text),
+			ContextInfo: info,
+		}}
+	}
+
+	edit := m.cli.BuildEdit(jid, types.MessageID(target.ID), content)
+	at := time.UnixMilli(edit.GetEditedMessage().GetMessage().GetProtocolMessage().GetTimestampMS())
+
+	m.updateChatMessage(room.ID, target.ID, func(msg *messageview.Message) {
+		msg.Body = body
+		msg.Mentioned = mentioned
+		msg.Edited = true
+	})
+
+	cli := m.cli
+	stored := summarizeMessage(content)
+	return m.dispatchSend(func() tea.Msg {
+		_, err := cli.SendMessage(context.Background(), jid, edit)
+		return editSentMsg{chat: room.ID, body: stored, shown: body, at: at, previous: target, err: err}
+	})
+}
+
+func (m *model) applyEditSent(msg editSentMsg) tea.Cmd {
+	id := msg.previous.ID
+	if msg.err != nil {
+		m.pushDevLog(i18n.T("err.edit", msg.chat, msg.err))
+		m.updateChatMessage(msg.chat, id, func(item *messageview.Message) {
+			item.Body = msg.previous.Body
+			item.Mentioned = msg.previous.Mentioned
+			item.Edited = msg.previous.Edited
+		})
+		return nil
+	}
+	return tea.Batch(m.persistEdit(msg.chat, id, msg.body, msg.at, changeSender{chat: msg.chat, own: true}), m.updateRoomPreview(msg.chat, id, msg.shown))
+}
+
+func (m *model) startRevoke() {
+	selected := m.messages.Selected()
+	if !revocable(selected) {
+		return
+	}
+
+	m.revoke = revokeState{confirming: true, chat: m.messages.ChatID, message: *selected}
+	m.composer.Blur()
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+}
+
+func (m *model) closeRevokeConfirm() {
+	m.revoke = revokeState{}
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+}
+
+func (m model) updateRevokeConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		return m, m.sendRevoke()
+
+	case "n", "N", "esc":
+		m.closeRevokeConfirm()
+
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// sendRevoke deletes the confirmed message for everyone. It shows as
+// deleted right away and comes back if sending fails.
+func (m *model) sendRevoke() tea.Cmd {
+	chat, target := m.revoke.chat, m.revoke.message
+	m.closeRevokeConfirm()
+
+	jid, err := types.ParseJID(chat)
+	if err != nil || m.cli == nil {
+		return nil
+	}
+	m.updateChatMessage(chat, target.ID, markDeleted)
+
+	cli := m.cli
+	revoke := cli.BuildRevoke(jid, types.EmptyJID, types.MessageID(target.ID))
+	return m.dispatchSend(func() tea.Msg {
+		_, err := cli.SendMessage(context.Background(), jid, revoke)
+		return revokeSentMsg{chat: chat, previous: target, err: err}
+	})
+}
+
+func (m *model) applyRevokeSent(msg revokeSentMsg) tea.Cmd {
+	if msg.err != nil {
+		m.pushDevLog(i18n.T("err.revoke", msg.chat, msg.err))
+		m.updateChatMessage(msg.chat, msg.previous.ID, func(item *messageview.Message) {
+			*item = msg.previous
+		})
+		return nil
+	}
+	return m.applyRevoke(msg.chat, msg.previous.ID, changeSender{chat: msg.chat, own: true})
+}
+
+func (m model) revokeConfirmView(width int) string {
+	quote := previewLine(strings.Join(strings.Fields(m.revoke.message.Body), " "), width-4)
+	return connDownStyle.Render(i18n.T("revoke.confirm", quote)) + "\n" + subtleStyle.Render(i18n.T("revoke.keys"))
+}
@@ -0,0 +1,37 @@
+package tui
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/9d4/watui/messageview"
+)
+
+func TestEditKeepsMentions(t *testing.T) {
+	m := New(nil, nil, Options{})
+	m.contactNames["6281234@s.whatsapp.net"] = "Ann"
+	m.messages = m.messages.SetChat("123-456@g.us", []messageview.Message{{
+		ID:        "m1",
+		Body:      "hi @Ann and @6289999",
+		Time:      time.Now(),
+		FromMe:    true,
+		Status:    messageview.StatusSent,
+		Mentioned: []string{"6281234@s.whatsapp.net", "6289999@s.whatsapp.net"},
+	}})
+
+	m.startEdit()
+	if !m.edit.active {
+		t.Fatal("edit did not start")
+	}
+
+	m.composer.SetValue(m.composer.Value() + "!")
+	text, mentioned := m.wireMentions(m.composer.Value())
+	if want := "hi @6281234 and @6289999!"; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+	slices.Sort(mentioned)
+	if want := []string{"6281234@s.whatsapp.net", "6289999@s.whatsapp.net"}; !slices.Equal(mentioned, want) {
+		t.Errorf("mentioned = %v, want %v", mentioned, want)
+	}
+}
@@ -0,0 +1,179 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/9d4/watui/i18n"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const maxGroupPanelWidth = 36
+
+var groupPanelStyle = lipgloss.NewStyle().
+	PaddingLeft(1).
+	BorderStyle(lipgloss.NormalBorder()).
+	BorderLeft(true)
+
+type groupInfoMsg struct {
+	jid  string
+	info *types.GroupInfo
+	err  error
+}
+
+type joinedGroupsMsg struct {
+	groups []*types.GroupInfo
+	err    error
+}
+
+func (m model) loadJoinedGroups() tea.Cmd {
+	if m.cli == nil {
+		return nil
+	}
+
+	cli := m.cli
+	return func() tea.Msg {
+		groups, err := cli.GetJoinedGroups()
+		return joinedGroupsMsg{groups: groups, err: err}
+	}
+}
+
+func (m model) loadGroupInfo(jid string) tea.Cmd {
+	if m.cli == nil || !isGroup(jid) {
+		return nil
+	}
+
+	cli := m.cli
+	return func() tea.Msg {
+		parsed, err := types.ParseJID(jid)
+		if err != nil {
+			return groupInfoMsg{jid: jid, err: err}
+		}
+
+		info, err := cli.GetGroupInfo(parsed)
+		return groupInfoMsg{jid: jid, info: info, err: err}
+	}
+}
+
+// applyGroupInfo caches group metadata and takes the group name as the room
+// title.
+func (m *model) applyGroupInfo(info *types.GroupInfo) tea.Cmd {
+	if info == nil {
+		return nil
+	}
+
+	jid := info.JID.String()
+	m.groups[jid] = info
+	for _, p := range info.Participants {
+		if p.DisplayName != "" {
+			m.rememberPushName(participantJID(p), p.DisplayName)
+		}
+	}
+
+	return m.renameGroup(jid, info.Name)
+}
+
+func (m *model) renameGroup(jid, name string) tea.Cmd {
+	name = strings.TrimSpace(name)
+	if name == "" || m.chatTitles[jid] == name {
+		return nil
+	}
+
+	m.chatTitles[jid] = name
+	m.roomList = m.roomList.UpdateTitle(jid, name)
+	if room := m.roomList.Room(jid); room != nil {
+		return m.persistRoom(*room)
+	}
+	return nil
+}
+
+func participantJID(p types.GroupParticipant) string {
+	if !p.PhoneNumber.IsEmpty() {
+		return p.PhoneNumber.ToNonAD().String()
+	}
+	return p.JID.ToNonAD().String()
+}
+
+func (m *model) toggleGroupPanel() {
+	m.groupPanel = !m.groupPanel
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+}
+
+// groupPanelWidth is the width taken from the chat pane by the group panel,
+// or 0 when the panel is hidden.
+func (m model) groupPanelWidth(paneWidth int) int {
+	room := m.roomList.OpenedRoom()
+	if !m.groupPanel || room == nil || !isGroup(room.ID) {
+		return 0
+	}
+	return min(maxGroupPanelWidth, paneWidth/3)
+}
+
+func (m model) groupPanelView(jid string, width, height int) string {
+	inner := width - groupPanelStyle.GetHorizontalFrameSize()
+	if inner < 1 {
+		inner = 1
+	}
+
+	info := m.groups[jid]
+	if info == nil {
+		return groupPanelStyle.Width(inner).Height(height).Render(subtleStyle.Render(i18n.T("group.loading")))
+	}
+
+	wrap := lipgloss.NewStyle().Width(inner)
+	lines := []string{titleStyle.Render(wrap.Render(m.resolveTitle(jid, info.Name))), ""}
+
+	if topic := strings.TrimSpace(info.Topic); topic != "" {
+		lines = append(lines, wrap.Render(topic))
+	} else {
+		lines = append(lines, subtleStyle.Render(i18n.T("group.no_topic")))
+	}
+	lines = append(lines, "")
+
+	if !info.GroupCreated.IsZero() {
+		created := i18n.T("group.created", info.GroupCreated.Format("02 Jan 2006"))
+		if owner := info.OwnerPN; !owner.IsEmpty() {
+			created = i18n.T("group.created_by", info.GroupCreated.Format("02 Jan 2006"), m.senderName(owner.ToNonAD().String(), ""))
+		}
+		lines = append(lines, subtleStyle.Render(wrap.Render(created)))
+	}
+	lines = append(lines, subtleStyle.Render(i18n.T("group.members", len(info.Participants))), "")
+
+	var admins, members []string
+	for _, p := range info.Participants {
+		name := m.senderName(participantJID(p), p.DisplayName)
+		switch {
+		case p.IsSuperAdmin:
+			admins = append(admins, "★ "+name)
+		case p.IsAdmin:
+			admins = append(admins, "☆ "+name)
+		default:
+			members = append(members, "  "+name)
+		}
+	}
+	slices.Sort(members)
+
+	if len(admins) > 0 {
+		lines = append(lines, titleStyle.Render(i18n.T("group.admins")))
+		lines = append(lines, admins...)
+		lines = append(lines, "")
+	}
+	if len(members) > 0 {
+		lines = append(lines, titleStyle.Render(i18n.T("group.participants")))
+		lines = append(lines, members...)
+	}
+
+	content := strings.Split(lipgloss.JoinVertical(lipgloss.Left, lines...), "\n")
+	if len(content) > height && height > 1 {
+		hidden := len(content) - height + 1
+		content = append(content[:height-1], subtleStyle.Render(i18n.T("group.more", hidden)))
+	}
+
+	return groupPanelStyle.Width(inner).MaxWidth(width).Height(height).Render(strings.Join(content, "\n"))
+}
+
+func (m *model) logGroupError(jid string, err error) {
+	m.pushDevLog(i18n.T("err.group_info", jid, err))
+}
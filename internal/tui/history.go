@@ -0,0 +1,151 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/messageview"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mau.fi/whatsmeow"
+	waHistorySync "go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const (
+	olderPageSize      = 50
+	onDemandSyncLength = 50
+)
+
+type olderMessagesLoadedMsg struct {
+	chat     string
+	before   messageview.Message
+	messages []chatstore.Message
+}
+
+type historyRequestedMsg struct {
+	chat string
+	err  error
+}
+
+// loadOlderMessages pages backwards through the store, starting at the
+// oldest message shown in the viewport.
+func (m model) loadOlderMessages(req messageview.LoadOlderMsg) tea.Cmd {
+	return func() tea.Msg {
+		if m.store == nil || req.Before.ID == "" {
+			return olderMessagesLoadedMsg{chat: req.ChatID, before: req.Before}
+		}
+
+		cursor := chatstore.MessageCursor{Timestamp: req.Before.Time, ID: req.Before.ID}
+		messages, err := m.store.MessagesBefore(context.Background(), req.ChatID, cursor, olderPageSize)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.load_older"), err)}
+		}
+		return olderMessagesLoadedMsg{chat: req.ChatID, before: req.Before, messages: messages}
+	}
+}
+
+// applyOlderMessages prepends a page from the store, or falls back to asking
+// the phone for older history once the store has nothing left.
+func (m *model) applyOlderMessages(msg olderMessagesLoadedMsg) tea.Cmd {
+	if m.messages.ChatID != msg.chat {
+		return nil
+	}
+
+	if len(msg.messages) > 0 {
+		m.messages = m.messages.Prepend(m.viewMessages(msg.messages))
+		return nil
+	}
+
+	if msg.before.ID == "" {
+		m.messages = m.messages.SetExhausted()
+		return nil
+	}
+
+	return m.requestOnDemandHistory(msg.chat, msg.before)
+}
+
+func (m model) requestOnDemandHistory(chat string, before messageview.Message) tea.Cmd {
+	if m.cli == nil || m.cli.Store == nil || m.cli.Store.ID == nil {
+		return func() tea.Msg {
+			return historyRequestedMsg{chat: chat, err: errors.New(i18n.T("err.client_not_ready"))}
+		}
+	}
+
+	jid, err := types.ParseJID(chat)
+	if err != nil {
+		return func() tea.Msg {
+			return historyRequestedMsg{chat: chat, err: err}
+		}
+	}
+
+	cli := m.cli
+	return func() tea.Msg {
+		info := &types.MessageInfo{
+			MessageSource: types.MessageSource{Chat: jid, IsFromMe: before.FromMe},
+			ID:            types.MessageID(before.ID),
+			Timestamp:     before.Time,
+		}
+
+		req := cli.BuildHistorySyncRequest(info, onDemandSyncLength)
+		_, err := cli.SendMessage(context.Background(), cli.Store.ID.ToNonAD(), req, whatsmeow.SendRequestExtra{Peer: true})
+		return historyRequestedMsg{chat: chat, err: err}
+	}
+}
+
+// applyOnDemandHistory stores the messages of an on-demand history sync and
+// shows them in the viewport. Rooms are left untouched because these
+// messages are older than the current previews.
+func (m *model) applyOnDemandHistory(data *waHistorySync.HistorySync) tea.Cmd {
+	var (
+		stored    []chatstore.Message
+		reactions = make(map[string][]chatstore.Reaction)
+	)
+	for _, conv := range data.GetConversations() {
+		jid, err := types.ParseJID(conv.GetID())
+		if err != nil {
+			continue
+		}
+
+		records := conversationRecords(jid.String(), conv)
+		stored = append(stored, records...)
+
+		if chatReactions := m.conversationReactions(conv); len(chatReactions) > 0 {
+			m.mergeReactions(jid.String(), chatReactions)
+			reactions[jid.String()] = chatReactions
+		}
+
+		if m.messages.ChatID != jid.String() {
+			continue
+		}
+		if len(records) == 0 {
+			m.messages = m.messages.SetExhausted()
+			continue
+		}
+
+		slices.SortStableFunc(records, func(a, b chatstore.Message) int {
+			return a.Timestamp.Compare(b.Timestamp)
+		})
+		m.messages = m.messages.Prepend(m.viewMessages(records))
+	}
+
+	if m.store == nil || len(stored) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := m.store.SaveMessages(ctx, stored); err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_history"), err)}
+		}
+		for chat, chatReactions := range reactions {
+			if err := m.store.SaveReactions(ctx, chat, chatReactions); err != nil {
+				return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_reaction"), err)}
+			}
+		}
+		return nil
+	}
+}
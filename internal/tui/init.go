@@ -6,38 +6,36 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/9d4/watui/i18n"
 	tea "github.com/charmbracelet/bubbletea"
 	"go.mau.fi/whatsmeow"
 	"golang.org/x/term"
 )
 
-func (m model) initClient() tea.Cmd {
-	return func() tea.Msg {
-		d, err := m.wa.C.GetFirstDevice(context.Background())
-		if err != nil {
-			return errMsg{err: fmt.Errorf("gagal membaca device: %w", err)}
-		}
-
-		return clientReadyMsg{cli: whatsmeow.NewClient(d, m.wa.WaLog())}
-	}
+func (m model) startPairing() tea.Cmd {
+	return m.pair(func(code string) {
+		m.events <- qrCodeMsg{Code: code}
+	})
 }
 
-func (m model) startPairing() tea.Cmd {
+// pair connects the client for pairing and forwards QR channel events. Each
+// new QR code is handed to onCode.
+func (m model) pair(onCode func(code string)) tea.Cmd {
 	return func() tea.Msg {
 		if m.cli == nil {
-			return errMsg{err: errors.New("client belum siap")}
+			return errMsg{err: errors.New(i18n.T("err.client_not_ready"))}
 		}
 
 		qrChan, err := m.cli.GetQRChannel(context.Background())
 		if err != nil {
-			return errMsg{err: fmt.Errorf("gagal membuat qr channel: %w", err)}
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.qr_channel"), err)}
 		}
 
 		go func() {
 			for evt := range qrChan {
 				switch evt.Event {
 				case whatsmeow.QRChannelEventCode:
-					m.events <- qrCodeMsg{Code: evt.Code}
+					onCode(evt.Code)
 				case whatsmeow.QRChannelEventError:
 					m.events <- qrStatusMsg{Status: evt.Event, Err: evt.Error}
 				default:
@@ -47,7 +45,7 @@ func (m model) startPairing() tea.Cmd {
 		}()
 
 		if err := m.cli.Connect(); err != nil {
-			return errMsg{err: fmt.Errorf("gagal connect: %w", err)}
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.connect"), err)}
 		}
 
 		return nil
@@ -57,7 +55,7 @@ func (m model) startPairing() tea.Cmd {
 func (m model) connectClient() tea.Cmd {
 	return func() tea.Msg {
 		if m.cli == nil {
-			return errMsg{err: errors.New("client belum siap")}
+			return errMsg{err: errors.New(i18n.T("err.client_not_ready"))}
 		}
 
 		if m.cli.IsConnected() {
@@ -65,7 +63,7 @@ func (m model) connectClient() tea.Cmd {
 		}
 
 		if err := m.cli.Connect(); err != nil {
-			return errMsg{err: fmt.Errorf("gagal connect: %w", err)}
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.connect"), err)}
 		}
 
 		return nil
@@ -83,9 +81,9 @@ func (m model) Init() tea.Cmd {
 		m.roomList.Init(),
 		m.loading.Tick,
 		initialWindowSizeCmd(),
-		m.loadStoredRooms(),
-		m.initClient(),
+		m.openManager(),
 		m.waitEvents(),
+		m.startIdleCheck(),
 	)
 }
 
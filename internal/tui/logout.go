@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/9d4/watui/i18n"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// logoutState covers the confirmation of a manual logout and the screen
+// shown once the account is logged out, by us or from the phone.
+type logoutState struct {
+	confirming bool
+	reason     string
+	cleared    bool
+}
+
+type loggedOutMsg struct{}
+
+type chatDataClearedMsg struct {
+	err error
+}
+
+var confirmBoxStyle = lipgloss.NewStyle().
+	Padding(1, 3).
+	BorderStyle(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("9"))
+
+func (m *model) confirmLogout() {
+	if m.activeAccount() == nil {
+		return
+	}
+	m.logout.confirming = true
+	m.composer.Blur()
+}
+
+func (m model) updateLogoutConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.logout.confirming = false
+		m.state = stateLoading
+		m.statusMessage = i18n.T("status.logging_out")
+		return m, m.logoutClient()
+
+	case "n", "N", "esc", "q":
+		m.logout.confirming = false
+
+	case "ctrl+c":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m model) logoutClient() tea.Cmd {
+	cli := m.cli
+	var logout tea.Cmd
+	logout = func() tea.Msg {
+		if err := cli.Logout(context.Background()); err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.logout"), err), retry: logout}
+		}
+		return loggedOutMsg{}
+	}
+	return logout
+}
+
+// enterLoggedOut shows why the account is gone. The chat store stays open
+// so the user can still choose to clear it.
+func (m *model) enterLoggedOut(reason string) {
+	m.state = stateLoggedOut
+	m.logout = logoutState{reason: reason}
+	m.conn = connectionState{status: connOffline, gen: m.conn.gen + 1}
+	m.composer.Blur()
+	m.syncOverlay = syncOverlayState{}
+}
+
+func logoutReason(evt *events.LoggedOut) string {
+	switch evt.Reason {
+	case events.ConnectFailureLoggedOut:
+		return i18n.T("logout.reason_removed")
+	case events.ConnectFailureMainDeviceGone:
+		return i18n.T("logout.reason_locked")
+	case events.ConnectFailureUnknownLogout:
+		return i18n.T("logout.reason_banned")
+	default:
+		return i18n.T("logout.reason_other", evt.Reason)
+	}
+}
+
+func (m model) updateLoggedOut(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter", "p":
+		// A fresh device replaces the logged out client, which lands on
+		// the welcome screen to pair again.
+		return m, m.addAccount()
+
+	case "d":
+		if m.logout.cleared || m.store == nil {
+			break
+		}
+		store := m.store
+		return m, func() tea.Msg {
+			return chatDataClearedMsg{err: store.Clear(context.Background())}
+		}
+
+	case "a":
+		return m, m.loadAccounts()
+
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m *model) applyChatDataCleared(msg chatDataClearedMsg) {
+	if msg.err != nil {
+		m.pushDevLog(fmt.Sprintf("%s: %v", i18n.T("err.clear_chat_data"), msg.err))
+		return
+	}
+	m.logout.cleared = true
+}
+
+func (m model) logoutConfirmView() string {
+	body := lipgloss.JoinVertical(lipgloss.Center,
+		titleStyle.Render(i18n.T("logout.confirm_title")),
+		"",
+		i18n.T("logout.confirm_desc"),
+		"",
+		subtleStyle.Render(i18n.T("logout.confirm_keys")),
+	)
+	return confirmBoxStyle.Render(body)
+}
+
+func (m model) loggedOutView() string {
+	width := min(m.contentWidth(), 72)
+
+	data := i18n.T("logout.data_kept")
+	if m.logout.cleared {
+		data = i18n.T("logout.data_cleared")
+	}
+
+	keys := i18n.T("logout.keys")
+	if !m.logout.cleared && m.store != nil {
+		keys = i18n.T("logout.keys_clear")
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(i18n.T("logout.title")),
+		"",
+		lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Width(width).Render(m.logout.reason),
+		lipgloss.NewStyle().Width(width).Render(data),
+		"",
+		subtleStyle.Render(keys),
+	)
+}
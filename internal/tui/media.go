@@ -0,0 +1,191 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/media"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+// mediaState backs the save prompt and the last download result shown above
+// the composer.
+type mediaState struct {
+	saving bool
+	input  textinput.Model
+	chat   string
+	id     string
+	notice string
+}
+
+type mediaDoneMsg struct {
+	notice string
+	err    error
+}
+
+func newSavePathInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = i18n.T("media.save_prompt")
+	return ti
+}
+
+// selectedMedia returns the selected message when it carries an attachment.
+func (m *model) selectedMedia() (chat, id string, ok bool) {
+	selected := m.messages.Selected()
+	if selected == nil || selected.ID == "" {
+		return "", "", false
+	}
+	if !selected.Media {
+		m.media.notice = i18n.T("media.none")
+		return "", "", false
+	}
+	return m.messages.ChatID, selected.ID, true
+}
+
+func (m *model) openMedia() tea.Cmd {
+	chat, id, ok := m.selectedMedia()
+	if !ok {
+		return nil
+	}
+
+	m.media.notice = i18n.T("media.downloading")
+	command := m.openCommand
+	return m.withAttachment(chat, id, func(path string, _ media.Attachment) mediaDoneMsg {
+		if err := media.Open(path, command); err != nil {
+			return mediaDoneMsg{err: fmt.Errorf("%s: %w", i18n.T("err.media_open"), err)}
+		}
+		return mediaDoneMsg{notice: i18n.T("media.opened", filepath.Base(path))}
+	})
+}
+
+func (m *model) startSaveMedia() tea.Cmd {
+	chat, id, ok := m.selectedMedia()
+	if !ok {
+		return nil
+	}
+
+	m.media.saving = true
+	m.media.chat = chat
+	m.media.id = id
+	m.media.notice = ""
+	m.media.input.SetValue(defaultSaveDir())
+	m.media.input.CursorEnd()
+	m.composer.Blur()
+	return m.media.input.Focus()
+}
+
+func (m *model) closeSavePrompt() {
+	m.media.saving = false
+	m.media.input.Blur()
+}
+
+func (m model) updateSavePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.closeSavePrompt()
+		return m, nil
+
+	case "enter":
+		dst := expandPath(strings.TrimSpace(m.media.input.Value()))
+		if dst == "" {
+			return m, nil
+		}
+		m.closeSavePrompt()
+		m.media.notice = i18n.T("media.downloading")
+		return m, m.withAttachment(m.media.chat, m.media.id, func(path string, attachment media.Attachment) mediaDoneMsg {
+			saved, err := media.Save(path, dst, attachment.Name())
+			if err != nil {
+				return mediaDoneMsg{err: fmt.Errorf("%s: %w", i18n.T("err.media_save"), err)}
+			}
+			return mediaDoneMsg{notice: i18n.T("media.saved", saved)}
+		})
+	}
+
+	var cmd tea.Cmd
+	m.media.input, cmd = m.media.input.Update(msg)
+	return m, cmd
+}
+
+// withAttachment loads a stored message, makes sure its attachment is in the
+// cache and hands the cached file to then.
+func (m model) withAttachment(chat, id string, then func(path string, attachment media.Attachment) mediaDoneMsg) tea.Cmd {
+	if m.cli == nil || m.store == nil || m.mediaCache == nil {
+		return nil
+	}
+
+	cli, store, cache := m.cli, m.store, m.mediaCache
+	return func() tea.Msg {
+		ctx := context.Background()
+		stored, err := store.Message(ctx, chat, id)
+		if err == nil && (stored == nil || len(stored.Raw) == 0) {
+			err = errors.New(i18n.T("err.media_missing"))
+		}
+		if err != nil {
+			return mediaDoneMsg{err: fmt.Errorf("%s: %w", i18n.T("err.media_download"), err)}
+		}
+
+		var msg waE2E.Message
+		if err := proto.Unmarshal(stored.Raw, &msg); err != nil {
+			return mediaDoneMsg{err: fmt.Errorf("%s: %w", i18n.T("err.media_download"), err)}
+		}
+		attachment, ok := media.Find(&msg)
+		if !ok {
+			return mediaDoneMsg{err: fmt.Errorf("%s: %w", i18n.T("err.media_download"), media.ErrNoAttachment)}
+		}
+
+		path, err := cache.Fetch(ctx, cli, attachment)
+		if err != nil {
+			return mediaDoneMsg{err: fmt.Errorf("%s: %w", i18n.T("err.media_download"), err)}
+		}
+		return then(path, attachment)
+	}
+}
+
+func (m *model) applyMediaDone(msg mediaDoneMsg) {
+	if msg.err != nil {
+		m.media.notice = "⚠ " + msg.err.Error()
+		return
+	}
+	m.media.notice = msg.notice
+}
+
+func (m model) savePromptView() string {
+	return m.media.input.View() + "\n" + subtleStyle.Render(i18n.T("media.save_hint"))
+}
+
+// defaultSaveDir suggests ~/Downloads, or the home directory without one.
+func defaultSaveDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	dir := filepath.Join(home, "Downloads")
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = home
+	}
+	return dir + string(filepath.Separator)
+}
+
+func expandPath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"go.mau.fi/whatsmeow/types"
+)
+
+const maxMentionSuggestions = 5
+
+var mentionSelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+
+// mentionState tracks the group members mentioned in the draft and the
+// completion list shown while an "@" is typed.
+type mentionState struct {
+	// inserted maps the "@Name" labels put into the draft to member JIDs.
+	inserted map[string]string
+
+	start      int
+	candidates []mentionCandidate
+	cursor     int
+}
+
+type mentionCandidate struct {
+	jid  string
+	name string
+}
+
+func (s mentionState) suggesting() bool {
+	return len(s.candidates) > 0
+}
+
+// updateMentionSuggestions completes the "@" word at the end of the draft
+// against the participants of the open group.
+func (m *model) updateMentionSuggestions() {
+	m.mention.candidates = nil
+	m.mention.cursor = 0
+
+	room := m.roomList.OpenedRoom()
+	if room == nil {
+		return
+	}
+	info := m.groups[room.ID]
+	if info == nil {
+		return
+	}
+
+	value := m.composer.Value()
+	start := strings.LastIndex(value, "@")
+	if start < 0 {
+		return
+	}
+	if start > 0 && !unicode.IsSpace(rune(value[start-1])) {
+		return
+	}
+	query := strings.ToLower(value[start+1:])
+	if strings.ContainsFunc(query, unicode.IsSpace) {
+		return
+	}
+
+	var candidates []mentionCandidate
+	for _, p := range info.Participants {
+		jid := participantJID(p)
+		if m.isOwnJID(jid) {
+			continue
+		}
+
+		name := strings.TrimPrefix(m.senderName(jid, p.DisplayName), "+")
+		user := mentionUser(jid)
+		if strings.Contains(strings.ToLower(name), query) || strings.HasPrefix(user, query) {
+			candidates = append(candidates, mentionCandidate{jid: jid, name: name})
+		}
+	}
+	slices.SortFunc(candidates, func(a, b mentionCandidate) int {
+		return strings.Compare(strings.ToLower(a.name), strings.ToLower(b.name))
+	})
+	if len(candidates) > maxMentionSuggestions {
+		candidates = candidates[:maxMentionSuggestions]
+	}
+
+	m.mention.start = start
+	m.mention.candidates = candidates
+}
+
+func (m *model) moveMentionCursor(delta int) {
+	m.mention.cursor = max(0, min(len(m.mention.candidates)-1, m.mention.cursor+delta))
+}
+
+// acceptMention replaces the "@" word being typed with the chosen member.
+func (m *model) acceptMention() {
+	if !m.mention.suggesting() {
+		return
+	}
+
+	chosen := m.mention.candidates[m.mention.cursor]
+	label := "@" + chosen.name
+	if m.mention.inserted == nil {
+		m.mention.inserted = make(map[string]string)
+	}
+	m.mention.inserted[label] = chosen.jid
+
+	m.composer.SetValue(m.composer.Value()[:m.mention.start] + label + " ")
+	m.mention.candidates = nil
+}
+
+// restoreMentions lets the labels resolveMentions put into a message body
+// be wired again, for an edit of that message.
+func (m *model) restoreMentions(mentioned []string) {
+	for _, jid := range mentioned {
+		parsed, err := types.ParseJID(jid)
+		if err != nil || parsed.User == "" {
+			continue
+		}
+		if m.mention.inserted == nil {
+			m.mention.inserted = make(map[string]string)
+		}
+		m.mention.inserted[m.mentionLabel(parsed)] = parsed.ToNonAD().String()
+	}
+}
+
+// wireMentions rewrites the "@Name" labels still present in body to the
+// "@<number>" form WhatsApp expects and returns the mentioned JIDs.
+func (m model) wireMentions(body string) (string, []string) {
+	labels := make([]string, 0, len(m.mention.inserted))
+	for label := range m.mention.inserted {
+		labels = append(labels, label)
+	}
+	// Longer labels first, so "@Ann" does not eat into "@Anna".
+	slices.SortFunc(labels, func(a, b string) int {
+		return len(b) - len(a)
+	})
+
+	var mentioned []string
+	for _, label := range labels {
+		if !strings.Contains(body, label) {
+			continue
+		}
+
+		jid := m.mention.inserted[label]
+		body = strings.ReplaceAll(body, label, "@"+mentionUser(jid))
+		if !slices.Contains(mentioned, jid) {
+			mentioned = append(mentioned, jid)
+		}
+	}
+	return body, mentioned
+}
+
+func mentionUser(jid string) string {
+	parsed, err := types.ParseJID(jid)
+	if err != nil {
+		return ""
+	}
+	return parsed.User
+}
+
+func (m model) mentionSuggestionsView() string {
+	lines := make([]string, 0, len(m.mention.candidates))
+	for i, candidate := range m.mention.candidates {
+		line := "  @" + candidate.name + subtleStyle.Render(" +"+mentionUser(candidate.jid))
+		if i == m.mention.cursor {
+			line = "› " + mentionSelectedStyle.Render("@"+candidate.name) + subtleStyle.Render(" +"+mentionUser(candidate.jid))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
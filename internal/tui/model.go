@@ -3,13 +3,22 @@ package tui
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
 	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/media"
+	"github.com/9d4/watui/messageview"
+	"github.com/9d4/watui/notify"
+	"github.com/9d4/watui/preview"
 	"github.com/9d4/watui/roomlist"
 	"github.com/9d4/watui/wa"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"go.mau.fi/whatsmeow"
 	"go.mau.fi/whatsmeow/appstate"
@@ -22,37 +31,92 @@ const (
 	stateLoading sessionState = iota
 	stateWelcome
 	statePairing
+	statePhoneInput
 	stateHistorySync
 	stateConnecting
 	stateChats
+	stateAccounts
 	stateError
+	stateLoggedOut
 )
 
 type model struct {
 	state    sessionState
 	roomList roomlist.Model
+	messages messageview.Model
+	composer textarea.Model
 
 	loading        spinner.Model
 	syncProgress   progress.Model
 	statusMessage  string
 	historyMessage string
-
-	wa       *wa.Manager
-	store    *chatstore.Store
-	events   chan any
-	waQRCode string
-
-	qrStatus string
-
-	width         int
-	height        int
-	devMode       bool
-	devLogs       []string
-	historyReady  bool
-	syncOverlay   syncOverlayState
-	chatTitles    map[string]string
-	chatSummaries map[string][]string
-	contactNames  map[string]string
+	errorHint      string
+	retry          tea.Cmd
+
+	wa            *wa.Manager
+	managerOpener ManagerOpener
+	store         *chatstore.Store
+	storeOpener   StoreOpener
+	events        chan any
+	waQRCode      string
+
+	qrStatus   string
+	phoneInput textinput.Model
+	pairCode   string
+
+	accounts      []account
+	accountCursor int
+
+	search      searchState
+	messageInfo messageInfoState
+	reply       replyState
+	mention     mentionState
+	media       mediaState
+	attach      attachState
+	upload      uploadState
+	conn        connectionState
+	logout      logoutState
+	quit        quitState
+	presence    presenceState
+	typing      typingState
+	react       reactionState
+	edit        editState
+	revoke      revokeState
+
+	// away is set while we told WhatsApp we are unavailable.
+	away      bool
+	lastInput time.Time
+
+	mediaCache   *media.Cache
+	openCommand  string
+	imagePreview preview.Protocol
+	notifier     *notify.Notifier
+	hideTyping   bool
+	idleTimeout  time.Duration
+	stayOnline   bool
+
+	// terminal takes notifications and kitty images, written outside the
+	// rendered views.
+	terminal io.Writer
+
+	// focused follows terminal focus reports; terminals that do not send
+	// them leave it true.
+	focused bool
+
+	width        int
+	height       int
+	devMode      bool
+	devLogs      []string
+	historyReady bool
+	syncOverlay  syncOverlayState
+	chatTitles   map[string]string
+	chatMessages map[string][]messageview.Message
+	contactNames map[string]string
+	groups       map[string]*types.GroupInfo
+	groupPanel   bool
+
+	// reactions holds, per chat and message, who reacted with what.
+	reactions map[string]map[string]messageReactions
 
 	cli *whatsmeow.Client
 }
@@ -62,30 +126,74 @@ type syncOverlayState struct {
 	label  string
 }
 
-func New(wa *wa.Manager, store *chatstore.Store, devMode bool) model {
+// Options are the preferences taken from the command line and the config
+// file.
+type Options struct {
+	DevMode      bool
+	MediaDir     string
+	OpenCommand  string
+	ImagePreview preview.Protocol
+	Notify       notify.Method
+	HideTyping   bool
+
+	// IdleTimeout of zero never goes unavailable for lack of input.
+	IdleTimeout        time.Duration
+	OnlineInBackground bool
+}
+
+func New(openManager ManagerOpener, openStore StoreOpener, opts Options) model {
+	terminal := notify.Terminal()
 	return model{
 		state:         stateLoading,
 		loading:       spinner.New(spinner.WithSpinner(spinner.Dot)),
 		syncProgress:  progress.New(progress.WithDefaultGradient()),
 		roomList:      roomlist.New(),
-		statusMessage: "Menyiapkan WhatsApp session...",
-		devMode:       devMode,
-		wa:            wa,
-		store:         store,
+		messages:      newMessageView(opts.ImagePreview, terminal),
+		composer:      newComposer(),
+		phoneInput:    newPhoneInput(),
+		search:        searchState{input: newSearchInput()},
+		media:         mediaState{input: newSavePathInput()},
+		attach:        attachState{picker: newFilePicker()},
+		upload:        uploadState{bar: progress.New(progress.WithDefaultGradient())},
+		mediaCache:    media.NewCache(opts.MediaDir),
+		openCommand:   opts.OpenCommand,
+		imagePreview:  opts.ImagePreview,
+		notifier:      notify.New(opts.Notify, terminal),
+		terminal:      terminal,
+		focused:       true,
+		hideTyping:    opts.HideTyping,
+		idleTimeout:   opts.IdleTimeout,
+		stayOnline:    opts.OnlineInBackground,
+		lastInput:     time.Now(),
+		statusMessage: i18n.T("status.preparing_wa"),
+		devMode:       opts.DevMode,
+		managerOpener: openManager,
+		storeOpener:   openStore,
 		events:        make(chan any),
 		chatTitles:    make(map[string]string),
-		chatSummaries: make(map[string][]string),
+		chatMessages:  make(map[string][]messageview.Message),
 		contactNames:  make(map[string]string),
+		groups:        make(map[string]*types.GroupInfo),
+		reactions:     make(map[string]map[string]messageReactions),
 	}
 }
 
+func newMessageView(protocol preview.Protocol, terminal io.Writer) messageview.Model {
+	view := messageview.New()
+	if protocol != preview.ProtocolText {
+		view = view.SetImageRenderer(preview.New(protocol, terminal))
+	}
+	return view
+}
+
 // Event wrapper from whatsmeow
 type waEvent struct {
 	evt any
 }
 
 type clientReadyMsg struct {
-	cli *whatsmeow.Client
+	cli   *whatsmeow.Client
+	store *chatstore.Store
 }
 
 type qrCodeMsg struct {
@@ -98,7 +206,9 @@ type qrStatusMsg struct {
 }
 
 type errMsg struct {
-	err error
+	err   error
+	hint  string
+	retry tea.Cmd
 }
 
 func (e errMsg) Error() string {
@@ -137,7 +247,7 @@ func (m model) loadStoredRooms() tea.Cmd {
 	return func() tea.Msg {
 		rooms, syncState, err := m.store.LoadAll(context.Background())
 		if err != nil {
-			return errMsg{err: fmt.Errorf("gagal memuat chat: %w", err)}
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.load_chats"), err)}
 		}
 		return roomsLoadedMsg{rooms: rooms, sync: syncState}
 	}
@@ -152,7 +262,7 @@ func (m model) loadContacts() tea.Cmd {
 		ctx := context.Background()
 		contacts, err := m.cli.Store.Contacts.GetAllContacts(ctx)
 		if err != nil {
-			return errMsg{err: fmt.Errorf("gagal memuat kontak: %w", err)}
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.load_contacts"), err)}
 		}
 
 		names := make(map[string]string, len(contacts))
@@ -174,7 +284,7 @@ func (m model) syncContactsAppState() tea.Cmd {
 	return func() tea.Msg {
 		err := m.cli.FetchAppState(context.Background(), appstate.WAPatchCriticalUnblockLow, false, true)
 		if err != nil {
-			return errMsg{err: fmt.Errorf("gagal sync kontak: %w", err)}
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.sync_contacts"), err)}
 		}
 		return m.loadContacts()()
 	}
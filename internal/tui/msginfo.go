@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/messageview"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// messageInfoState backs the view listing which group members received and
+// read one of our messages.
+type messageInfoState struct {
+	active   bool
+	chat     string
+	message  messageview.Message
+	receipts []chatstore.Receipt
+	err      error
+}
+
+type messageInfoLoadedMsg struct {
+	chat     string
+	id       string
+	receipts []chatstore.Receipt
+	err      error
+}
+
+func isGroup(jid string) bool {
+	parsed, err := types.ParseJID(jid)
+	return err == nil && parsed.Server == types.GroupServer
+}
+
+func (m *model) openMessageInfo() tea.Cmd {
+	selected := m.messages.Selected()
+	if selected == nil || !selected.FromMe || selected.ID == "" || !isGroup(m.messages.ChatID) {
+		return nil
+	}
+
+	m.messageInfo = messageInfoState{
+		active:  true,
+		chat:    m.messages.ChatID,
+		message: *selected,
+	}
+	m.composer.Blur()
+	return m.loadMessageInfo(m.messages.ChatID, selected.ID)
+}
+
+func (m model) loadMessageInfo(chat, id string) tea.Cmd {
+	store := m.store
+	return func() tea.Msg {
+		receipts, err := store.Receipts(context.Background(), chat, id)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", i18n.T("err.load_receipts"), err)
+		}
+		return messageInfoLoadedMsg{chat: chat, id: id, receipts: receipts, err: err}
+	}
+}
+
+// reloadMessageInfo refreshes the info view when a receipt arrives for the
+// message it shows.
+func (m model) reloadMessageInfo(chat string, ids []string) tea.Cmd {
+	info := m.messageInfo
+	if !info.active || info.chat != chat || !slices.Contains(ids, info.message.ID) {
+		return nil
+	}
+	return m.loadMessageInfo(chat, info.message.ID)
+}
+
+func (m *model) applyMessageInfo(msg messageInfoLoadedMsg) {
+	info := &m.messageInfo
+	if !info.active || info.chat != msg.chat || info.message.ID != msg.id {
+		return
+	}
+
+	info.receipts = msg.receipts
+	info.err = msg.err
+}
+
+func (m model) updateMessageInfo(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc", "q", "I":
+		m.messageInfo = messageInfoState{}
+	}
+
+	return m, nil
+}
+
+func (m model) messageInfoView(width int) string {
+	info := m.messageInfo
+	width -= 4
+	if width < 1 {
+		width = 1
+	}
+
+	sections := []string{
+		titleStyle.Render(i18n.T("info.title")),
+		"",
+		lipgloss.NewStyle().Width(width).Render(info.message.Body),
+		subtleStyle.Render(info.message.Time.Format("02 Jan 15:04")),
+		"",
+	}
+
+	var read, delivered []string
+	for _, receipt := range info.receipts {
+		name := m.senderName(receipt.Participant, "")
+		switch {
+		case !receipt.ReadAt.IsZero():
+			read = append(read, receiptLine(name, receipt.ReadAt))
+		case !receipt.DeliveredAt.IsZero():
+			delivered = append(delivered, receiptLine(name, receipt.DeliveredAt))
+		}
+	}
+
+	switch {
+	case info.err != nil:
+		sections = append(sections, lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Width(width).Render(info.err.Error()))
+	case len(read) == 0 && len(delivered) == 0:
+		sections = append(sections, subtleStyle.Render(i18n.T("info.none")))
+	default:
+		if len(read) > 0 {
+			sections = append(sections, titleStyle.Render(i18n.T("info.read_by")))
+			sections = append(sections, read...)
+			sections = append(sections, "")
+		}
+		if len(delivered) > 0 {
+			sections = append(sections, titleStyle.Render(i18n.T("info.delivered_to")))
+			sections = append(sections, delivered...)
+			sections = append(sections, "")
+		}
+	}
+
+	sections = append(sections, "", subtleStyle.Render(i18n.T("info.hint")))
+	return lipgloss.NewStyle().Padding(0, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+func receiptLine(name string, at time.Time) string {
+	return "  " + name + subtleStyle.Render(" · "+at.Format("02 Jan 15:04"))
+}
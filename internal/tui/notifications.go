@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/roomlist"
+	tea "github.com/charmbracelet/bubbletea"
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+type notifySentMsg struct {
+	err error
+}
+
+// notifyMessage tells the user about an incoming message, unless its chat
+// is on screen in a focused terminal or muted on the phone.
+func (m model) notifyMessage(evt *events.Message, room roomlist.Room) tea.Cmd {
+	if !m.notifier.Enabled() || m.cli == nil || evt.Info.IsFromMe {
+		return nil
+	}
+	if evt.Message.GetProtocolMessage() != nil || evt.Message.GetReactionMessage() != nil {
+		return nil
+	}
+	if (m.focused && m.roomOpen(room.ID)) || room.Muted() {
+		return nil
+	}
+
+	body := room.LastMessage
+	if isGroup(room.ID) {
+		body = m.senderFromEvent(evt) + ": " + body
+	}
+
+	cli, notifier, title := m.cli, m.notifier, room.Title
+	return func() tea.Msg {
+		if chatMuted(cli, evt.Info.Chat) {
+			return nil
+		}
+		return notifySentMsg{err: notifier.Notify(title, body)}
+	}
+}
+
+// chatMuted reads the mute setting synced from the phone's app state.
+func chatMuted(cli *whatsmeow.Client, chat types.JID) bool {
+	if cli.Store == nil || cli.Store.ChatSettings == nil {
+		return false
+	}
+
+	settings, err := cli.Store.ChatSettings.GetChatSettings(context.Background(), chat)
+	if err != nil || !settings.Found {
+		return false
+	}
+	return settings.MutedUntil.After(time.Now())
+}
+
+func (m *model) applyNotifySent(msg notifySentMsg) {
+	if msg.err != nil {
+		m.pushDevLog(i18n.T("err.notify", msg.err))
+	}
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/9d4/watui/i18n"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mau.fi/whatsmeow"
+)
+
+const pairClientName = "Chrome (Linux)"
+
+type pairCodeMsg struct {
+	Code string
+}
+
+func newPhoneInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "628123456789"
+	ti.Prompt = "+ "
+	ti.CharLimit = 20
+	return ti
+}
+
+// startPhonePairing connects like QR pairing but asks WhatsApp for a link
+// code as soon as the first QR code shows the connection is ready. Later QR
+// codes are ignored.
+func (m model) startPhonePairing(phone string) tea.Cmd {
+	var once sync.Once
+	return m.pair(func(string) {
+		once.Do(func() {
+			code, err := m.cli.PairPhone(context.Background(), phone, true, whatsmeow.PairClientChrome, pairClientName)
+			if err != nil {
+				// Drop the connection made for pairing, or the next attempt
+				// finds it still up.
+				m.cli.Disconnect()
+				m.events <- qrStatusMsg{
+					Status: whatsmeow.QRChannelEventError,
+					Err:    fmt.Errorf("%s: %w", i18n.T("err.pair_code"), err),
+				}
+				return
+			}
+			m.events <- pairCodeMsg{Code: code}
+		})
+	})
+}
+
+// normalizePhone drops the separators people type in phone numbers and
+// checks what is left is an international number: digits only, with the
+// country code rather than a trunk 0, at most 15 digits long.
+func normalizePhone(input string) (string, bool) {
+	phone := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')', '.':
+			return -1
+		}
+		return r
+	}, strings.TrimPrefix(strings.TrimSpace(input), "+"))
+
+	if len(phone) < 8 || len(phone) > 15 || phone[0] == '0' {
+		return "", false
+	}
+	for _, r := range phone {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return phone, true
+}
+
+func (m model) updatePhoneInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.phoneInput.Blur()
+		m.state = stateWelcome
+		return m, nil
+
+	case "enter":
+		phone, ok := normalizePhone(m.phoneInput.Value())
+		if !ok {
+			m.qrStatus = i18n.T("phone.invalid")
+			return m, nil
+		}
+
+		m.phoneInput.Blur()
+		m.state = statePairing
+		m.statusMessage = i18n.T("status.requesting_code")
+		m.qrStatus = ""
+		m.pairCode = ""
+		m.historyReady = false
+		return m, m.startPhonePairing(phone)
+	}
+
+	var cmd tea.Cmd
+	m.phoneInput, cmd = m.phoneInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) phoneInputView() string {
+	sections := []string{
+		titleStyle.Render(i18n.T("phone.title")),
+		"",
+		i18n.T("phone.prompt"),
+		m.phoneInput.View(),
+		"",
+		subtleStyle.Render(i18n.T("phone.hint")),
+	}
+
+	if m.qrStatus != "" {
+		sections = append(sections, "", subtleStyle.Render(m.qrStatus))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+func (m model) pairCodeView() string {
+	code := bigText(m.pairCode)
+	if code == "" {
+		code = strings.Join(strings.Split(m.pairCode, ""), " ")
+	}
+
+	sections := []string{
+		m.loading.View() + " " + i18n.T("pair.enter_code"),
+		subtleStyle.Render(i18n.T("pair.code_steps")),
+		"",
+		pairCodeStyle.Render(code),
+	}
+
+	if m.qrStatus != "" {
+		sections = append(sections, "", subtleStyle.Render(m.qrStatus))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Center, sections...)
+}
@@ -0,0 +1,376 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/9d4/watui/i18n"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+const (
+	// WhatsApp drops a composing state nobody refreshed after this long.
+	typingTimeout = 25 * time.Second
+
+	// We refresh our own composing state while keys keep coming, and pause
+	// once they stopped for typingIdle.
+	typingRefresh = 10 * time.Second
+	typingIdle    = 5 * time.Second
+)
+
+// presenceState holds what contacts told us: who is typing in which chat,
+// and who is online.
+type presenceState struct {
+	typing     map[string]map[string]chatActivity
+	users      map[string]userPresence
+	subscribed map[string]bool
+}
+
+type chatActivity struct {
+	media types.ChatPresenceMedia
+	at    time.Time
+}
+
+type userPresence struct {
+	online   bool
+	lastSeen time.Time
+}
+
+// typingState is the composing state we sent for the open room.
+type typingState struct {
+	chat    string
+	active  bool
+	sentAt  time.Time
+	lastKey time.Time
+}
+
+type presenceExpiredMsg struct{}
+
+type typingIdleMsg struct {
+	chat string
+}
+
+type chatPresenceSentMsg struct {
+	chat string
+	err  error
+}
+
+var typingStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Italic(true)
+
+// subscribePresence asks for online and last seen updates of a contact.
+// Groups only report typing, which needs no subscription.
+func (m *model) subscribePresence(jid string) tea.Cmd {
+	if m.cli == nil || isGroup(jid) || m.presence.subscribed[jid] {
+		return nil
+	}
+	target, err := types.ParseJID(jid)
+	if err != nil {
+		return nil
+	}
+
+	if m.presence.subscribed == nil {
+		m.presence.subscribed = make(map[string]bool)
+	}
+	m.presence.subscribed[jid] = true
+
+	cli := m.cli
+	return func() tea.Msg {
+		if err := cli.SubscribePresence(target); err != nil {
+			return chatPresenceSentMsg{chat: jid, err: err}
+		}
+		return nil
+	}
+}
+
+// resubscribePresence announces our presence, which WhatsApp wants before
+// it sends any, and renews the subscription of the open room: subscriptions
+// do not survive a reconnect.
+func (m *model) resubscribePresence() tea.Cmd {
+	m.presence.subscribed = nil
+
+	cmds := []tea.Cmd{m.sendPresence(m.ownPresence())}
+	if room := m.roomList.OpenedRoom(); room != nil {
+		cmds = append(cmds, m.subscribePresence(room.ID))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m model) sendPresence(state types.Presence) tea.Cmd {
+	cli := m.cli
+	if cli == nil || cli.Store.ID == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		_ = cli.SendPresence(state)
+		return nil
+	}
+}
+
+func (m *model) applyChatPresence(evt *events.ChatPresence) tea.Cmd {
+	if evt.IsFromMe {
+		return nil
+	}
+
+	chat := evt.Chat.String()
+	sender := eventSenderJID(evt.MessageSource).String()
+
+	if evt.State != types.ChatPresenceComposing {
+		delete(m.presence.typing[chat], sender)
+		return nil
+	}
+
+	if m.presence.typing == nil {
+		m.presence.typing = make(map[string]map[string]chatActivity)
+	}
+	if m.presence.typing[chat] == nil {
+		m.presence.typing[chat] = make(map[string]chatActivity)
+	}
+	m.presence.typing[chat][sender] = chatActivity{media: evt.Media, at: time.Now()}
+
+	return tea.Tick(typingTimeout, func(time.Time) tea.Msg {
+		return presenceExpiredMsg{}
+	})
+}
+
+func (m *model) applyPresence(evt *events.Presence) {
+	if m.presence.users == nil {
+		m.presence.users = make(map[string]userPresence)
+	}
+
+	jid := evt.From.ToNonAD().String()
+	m.presence.users[jid] = userPresence{online: !evt.Unavailable, lastSeen: evt.LastSeen}
+
+	// Someone who went offline stopped typing in every chat, whatever they
+	// sent last.
+	if evt.Unavailable {
+		for chat, senders := range m.presence.typing {
+			delete(senders, jid)
+			if len(senders) == 0 {
+				delete(m.presence.typing, chat)
+			}
+		}
+	}
+}
+
+// expireTyping forgets composing states nobody refreshed.
+func (m *model) expireTyping() {
+	now := time.Now()
+	for chat, senders := range m.presence.typing {
+		for sender, activity := range senders {
+			if now.Sub(activity.at) >= typingTimeout {
+				delete(senders, sender)
+			}
+		}
+		if len(senders) == 0 {
+			delete(m.presence.typing, chat)
+		}
+	}
+}
+
+// presenceLabel is shown in the header of chat: who is typing or recording,
+// or else whether the contact is online.
+func (m model) presenceLabel(chat string) string {
+	var typers, recorders []string
+	for sender, activity := range m.presence.typing[chat] {
+		if time.Since(activity.at) >= typingTimeout {
+			continue
+		}
+		if activity.media == types.ChatPresenceMediaAudio {
+			recorders = append(recorders, sender)
+		} else {
+			typers = append(typers, sender)
+		}
+	}
+
+	switch {
+	case len(recorders) > 0:
+		return typingStyle.Render(m.activityLabel(chat, recorders, "presence.recording"))
+	case len(typers) > 0:
+		return typingStyle.Render(m.activityLabel(chat, typers, "presence.typing"))
+	}
+
+	user, ok := m.presence.users[chat]
+	switch {
+	case !ok || isGroup(chat):
+		return ""
+	case user.online:
+		return i18n.T("presence.online")
+	case !user.lastSeen.IsZero():
+		return i18n.T("presence.last_seen", lastSeenLabel(user.lastSeen))
+	default:
+		return ""
+	}
+}
+
+// activityLabel names who is active in groups; in a direct chat the header
+// already does.
+func (m model) activityLabel(chat string, senders []string, key string) string {
+	label := i18n.T(key)
+	if !isGroup(chat) {
+		return label
+	}
+	if len(senders) > 1 {
+		return i18n.T("presence.many", len(senders), label)
+	}
+	return fmt.Sprintf("%s %s", m.senderName(senders[0], ""), label)
+}
+
+func lastSeenLabel(at time.Time) string {
+	now := time.Now()
+	if y, d := at.YearDay(), now.YearDay(); at.Year() == now.Year() && y == d {
+		return i18n.T("presence.today", at.Format("15:04"))
+	}
+	return at.Format("02 Jan 15:04")
+}
+
+// updateTyping tells the open chat we are composing while the draft has
+// text, and that we paused once it is cleared.
+func (m *model) updateTyping() tea.Cmd {
+	room := m.roomList.OpenedRoom()
+	if m.hideTyping || m.cli == nil || room == nil {
+		return nil
+	}
+	if strings.TrimSpace(m.composer.Value()) == "" {
+		return m.stopTyping()
+	}
+
+	now := time.Now()
+	m.typing.lastKey = now
+	if m.typing.active && m.typing.chat == room.ID && now.Sub(m.typing.sentAt) < typingRefresh {
+		return nil
+	}
+
+	m.typing = typingState{chat: room.ID, active: true, sentAt: now, lastKey: now}
+	return tea.Batch(m.sendChatPresence(room.ID, types.ChatPresenceComposing), typingIdleTick(room.ID, typingIdle))
+}
+
+// stopTyping sends paused for a composing state we sent.
+func (m *model) stopTyping() tea.Cmd {
+	if !m.typing.active {
+		return nil
+	}
+	chat := m.typing.chat
+	m.typing = typingState{}
+	return m.sendChatPresence(chat, types.ChatPresencePaused)
+}
+
+func (m *model) applyTypingIdle(msg typingIdleMsg) tea.Cmd {
+	if !m.typing.active || m.typing.chat != msg.chat {
+		return nil
+	}
+	if idle := time.Since(m.typing.lastKey); idle < typingIdle {
+		return typingIdleTick(msg.chat, typingIdle-idle)
+	}
+	return m.stopTyping()
+}
+
+func typingIdleTick(chat string, after time.Duration) tea.Cmd {
+	return tea.Tick(after, func(time.Time) tea.Msg {
+		return typingIdleMsg{chat: chat}
+	})
+}
+
+func (m model) sendChatPresence(chat string, state types.ChatPresence) tea.Cmd {
+	jid, err := types.ParseJID(chat)
+	if err != nil || m.cli == nil {
+		return nil
+	}
+
+	cli := m.cli
+	return func() tea.Msg {
+		return chatPresenceSentMsg{chat: chat, err: cli.SendChatPresence(jid, state, types.ChatPresenceMediaText)}
+	}
+}
+
+func (m *model) applyChatPresenceSent(msg chatPresenceSentMsg) {
+	if msg.err != nil {
+		m.pushDevLog(i18n.T("err.presence", msg.chat, msg.err))
+	}
+}
+
+type idleCheckMsg struct{}
+
+// idleCheck fires once the idle timeout may have run out.
+func idleCheck(after time.Duration) tea.Cmd {
+	return tea.Tick(after, func(time.Time) tea.Msg {
+		return idleCheckMsg{}
+	})
+}
+
+func (m model) startIdleCheck() tea.Cmd {
+	if m.idleTimeout <= 0 {
+		return nil
+	}
+	return idleCheck(m.idleTimeout)
+}
+
+func (m *model) applyIdleCheck() tea.Cmd {
+	if idle := time.Since(m.lastInput); idle < m.idleTimeout {
+		return idleCheck(m.idleTimeout - idle)
+	}
+	return tea.Batch(m.goAway(), idleCheck(m.idleTimeout))
+}
+
+// noteInput comes back from being away on any key, and reads what arrived
+// in the open room meanwhile.
+func (m *model) noteInput() tea.Cmd {
+	m.lastInput = time.Now()
+	return tea.Batch(m.comeBack(), m.readOpenRoom())
+}
+
+func (m *model) applyFocus(focused bool) tea.Cmd {
+	m.focused = focused
+	if focused {
+		return m.noteInput()
+	}
+	if m.stayOnline {
+		return nil
+	}
+	return m.goAway()
+}
+
+// goAway shows us as unavailable, so contacts do not see us online while
+// nobody is at the terminal.
+func (m *model) goAway() tea.Cmd {
+	if m.away {
+		return nil
+	}
+	m.away = true
+	return tea.Batch(m.stopTyping(), m.sendPresence(types.PresenceUnavailable))
+}
+
+func (m *model) comeBack() tea.Cmd {
+	if !m.away {
+		return nil
+	}
+	m.away = false
+	return m.sendPresence(types.PresenceAvailable)
+}
+
+// ownPresence is the presence to announce after connecting.
+func (m model) ownPresence() types.Presence {
+	if m.away {
+		return types.PresenceUnavailable
+	}
+	return types.PresenceAvailable
+}
+
+// Shutdown marks us unavailable before the program exits, so we do not
+// linger as online until the server times the connection out.
+func (m model) Shutdown() {
+	if m.cli == nil || m.cli.Store.ID == nil || !m.cli.IsConnected() {
+		return
+	}
+	if m.typing.active {
+		if jid, err := types.ParseJID(m.typing.chat); err == nil {
+			_ = m.cli.SendChatPresence(jid, types.ChatPresencePaused, types.ChatPresenceMediaText)
+		}
+	}
+	if !m.away {
+		_ = m.cli.SendPresence(types.PresenceUnavailable)
+	}
+}
@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"github.com/9d4/watui/i18n"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// quitState holds a quit back while sends queued offline would be lost
+// with it.
+type quitState struct {
+	confirming bool
+}
+
+// holdQuit asks for confirmation instead of quitting when msg quits and
+// the outbox is not empty. "q" only quits from the main key map, so it is
+// left to that.
+func (m *model) holdQuit(msg tea.KeyMsg) bool {
+	if msg.String() != "ctrl+c" || len(m.conn.outbox) == 0 {
+		return false
+	}
+	m.confirmQuit()
+	return true
+}
+
+func (m *model) confirmQuit() {
+	m.quit.confirming = true
+	m.composer.Blur()
+}
+
+func (m model) updateQuitConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "ctrl+c":
+		return m, tea.Quit
+
+	case "n", "N", "esc", "q":
+		m.quit.confirming = false
+	}
+
+	return m, nil
+}
+
+func (m model) quitConfirmView() string {
+	body := lipgloss.JoinVertical(lipgloss.Center,
+		titleStyle.Render(i18n.T("quit.confirm_title")),
+		"",
+		i18n.T("quit.confirm_desc", len(m.conn.outbox)),
+		"",
+		subtleStyle.Render(i18n.T("quit.confirm_keys")),
+	)
+	return confirmBoxStyle.Render(body)
+}
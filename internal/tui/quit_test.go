@@ -0,0 +1,42 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func quits(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+func TestQuitWithQueuedSends(t *testing.T) {
+	ctrlC := tea.KeyMsg{Type: tea.KeyCtrlC}
+
+	m := New(nil, nil, Options{})
+	if _, cmd := m.Update(ctrlC); !quits(cmd) {
+		t.Fatal("ctrl+c with an empty outbox did not quit")
+	}
+
+	m.conn.outbox = []tea.Cmd{func() tea.Msg { return nil }}
+	next, cmd := m.Update(ctrlC)
+	m = next.(model)
+	if quits(cmd) || !m.quit.confirming {
+		t.Fatal("ctrl+c with queued sends quit without asking")
+	}
+
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m = next.(model)
+	if quits(cmd) || m.quit.confirming {
+		t.Fatal("n did not cancel the quit")
+	}
+
+	m.confirmQuit()
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}); !quits(cmd) {
+		t.Error("y did not quit")
+	}
+}
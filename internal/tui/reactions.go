@@ -0,0 +1,369 @@
+package tui
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/messageview"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	waHistorySync "go.mau.fi/whatsmeow/proto/waHistorySync"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// reactionChoices are offered by the picker, the same quick row phones show.
+var reactionChoices = []string{"👍", "❤️", "😂", "😮", "😢", "🙏"}
+
+// messageReactions holds the reaction of each participant, by sender JID.
+type messageReactions map[string]chatstore.Reaction
+
+// reactionState backs the picker for the selected message.
+type reactionState struct {
+	picking bool
+	chat    string
+	target  messageview.Message
+	cursor  int
+}
+
+type reactionSentMsg struct {
+	chat     string
+	reaction chatstore.Reaction
+	previous chatstore.Reaction
+	err      error
+}
+
+var reactionCursorStyle = lipgloss.NewStyle().Reverse(true)
+
+// reactionFromEvent reads a reaction message of a live event.
+func reactionFromEvent(evt *events.Message) (chatstore.Reaction, bool) {
+	reaction := evt.Message.GetReactionMessage()
+	if reaction == nil || reaction.GetKey().GetID() == "" {
+		return chatstore.Reaction{}, false
+	}
+
+	return chatstore.Reaction{
+		MessageID: reaction.GetKey().GetID(),
+		SenderJID: eventSenderJID(evt.Info.MessageSource).String(),
+		Emoji:     reaction.GetText(),
+		Timestamp: senderTime(reaction.GetSenderTimestampMS(), evt.Info.Timestamp),
+	}, true
+}
+
+// conversationReactions collects the reactions of a history conversation:
+// those attached to their message and those synced as messages of their own.
+func (m *model) conversationReactions(conv *waHistorySync.Conversation) []chatstore.Reaction {
+	var reactions []chatstore.Reaction
+	for _, msg := range conv.GetMessages() {
+		info := msg.GetMessage()
+		if info == nil {
+			continue
+		}
+		sent := time.Unix(int64(info.GetMessageTimestamp()), 0)
+
+		for _, r := range info.GetReactions() {
+			sender := m.reactorJID(r.GetKey())
+			if sender == "" || r.GetText() == "" {
+				continue
+			}
+			reactions = append(reactions, chatstore.Reaction{
+				MessageID: info.GetKey().GetID(),
+				SenderJID: sender,
+				Emoji:     r.GetText(),
+				Timestamp: senderTime(r.GetSenderTimestampMS(), sent),
+			})
+		}
+
+		if r := info.GetMessage().GetReactionMessage(); r != nil && r.GetKey().GetID() != "" {
+			sender := historySenderJID(info)
+			if info.GetKey().GetFromMe() {
+				sender = m.ownJID()
+			}
+			reactions = append(reactions, chatstore.Reaction{
+				MessageID: r.GetKey().GetID(),
+				SenderJID: sender,
+				Emoji:     r.GetText(),
+				Timestamp: senderTime(r.GetSenderTimestampMS(), sent),
+			})
+		}
+	}
+	return reactions
+}
+
+// senderTime prefers the timestamp the sender put in a reaction or edit,
+// which orders changes to one message that arrive out of order.
+func senderTime(ms int64, fallback time.Time) time.Time {
+	if ms > 0 {
+		return time.UnixMilli(ms)
+	}
+	return fallback
+}
+
+// reactorJID returns who reacted, given the key of a history reaction.
+func (m model) reactorJID(key *waCommon.MessageKey) string {
+	if key.GetFromMe() {
+		return m.ownJID()
+	}
+	sender := key.GetParticipant()
+	if sender == "" {
+		sender = key.GetRemoteJID()
+	}
+	if parsed, err := types.ParseJID(sender); err == nil && sender != "" {
+		return parsed.ToNonAD().String()
+	}
+	return sender
+}
+
+func (m model) ownJID() string {
+	if m.cli == nil || m.cli.Store == nil || m.cli.Store.ID == nil {
+		return ""
+	}
+	return m.cli.Store.ID.ToNonAD().String()
+}
+
+// setReaction records a reaction in memory unless a newer one of the same
+// participant is already known. Removals stay as an empty emoji, so an
+// older reaction arriving later is ignored. It reports whether the shown
+// reaction changed.
+func (m *model) setReaction(chat string, r chatstore.Reaction) bool {
+	if r.MessageID == "" || r.SenderJID == "" {
+		return false
+	}
+
+	byMessage := m.reactions[chat]
+	current, ok := byMessage[r.MessageID][r.SenderJID]
+	if ok && current.Timestamp.After(r.Timestamp) {
+		return false
+	}
+
+	if byMessage == nil {
+		byMessage = make(map[string]messageReactions)
+		m.reactions[chat] = byMessage
+	}
+	if byMessage[r.MessageID] == nil {
+		byMessage[r.MessageID] = make(messageReactions)
+	}
+	byMessage[r.MessageID][r.SenderJID] = r
+	return current.Emoji != r.Emoji
+}
+
+// applyReaction records a reaction and redraws its message.
+func (m *model) applyReaction(chat string, r chatstore.Reaction) {
+	if !m.setReaction(chat, r) {
+		return
+	}
+	summary := m.reactionSummary(chat, r.MessageID)
+	m.updateChatMessage(chat, r.MessageID, func(msg *messageview.Message) {
+		msg.Reactions = summary
+	})
+}
+
+// mergeReactions adds stored reactions of a chat before its messages are
+// built. Whichever of a stored and a live reaction is newer wins.
+func (m *model) mergeReactions(chat string, reactions []chatstore.Reaction) {
+	for _, r := range reactions {
+		m.setReaction(chat, r)
+	}
+}
+
+// reactionSummary counts the reactions to a message per emoji, the most
+// used first.
+func (m model) reactionSummary(chat, id string) []messageview.Reaction {
+	var summary []messageview.Reaction
+	for sender, r := range m.reactions[chat][id] {
+		if r.Emoji == "" {
+			continue
+		}
+		i := slices.IndexFunc(summary, func(s messageview.Reaction) bool { return s.Emoji == r.Emoji })
+		if i < 0 {
+			summary = append(summary, messageview.Reaction{Emoji: r.Emoji})
+			i = len(summary) - 1
+		}
+		summary[i].Count++
+		summary[i].Mine = summary[i].Mine || m.isOwnJID(sender)
+	}
+
+	slices.SortFunc(summary, func(a, b messageview.Reaction) int {
+		if a.Count != b.Count {
+			return b.Count - a.Count
+		}
+		return cmp.Compare(a.Emoji, b.Emoji)
+	})
+	return summary
+}
+
+// ownReaction returns the emoji we put on a message, if any.
+func (m model) ownReaction(chat, id string) chatstore.Reaction {
+	for sender, r := range m.reactions[chat][id] {
+		if r.Emoji != "" && m.isOwnJID(sender) {
+			return r
+		}
+	}
+	return chatstore.Reaction{}
+}
+
+func (m model) persistReactions(chat string, reactions []chatstore.Reaction) tea.Cmd {
+	if m.store == nil || chat == "" || len(reactions) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := m.store.SaveReactions(ctx, chat, reactions); err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_reaction"), err)}
+		}
+		return nil
+	}
+}
+
+func (m *model) openReactionPicker() {
+	selected := m.messages.Selected()
+	if selected == nil || selected.ID == "" || selected.Pending || selected.Failed || selected.Deleted {
+		return
+	}
+
+	m.react = reactionState{
+		picking: true,
+		chat:    m.messages.ChatID,
+		target:  *selected,
+	}
+	if own := m.ownReaction(m.react.chat, selected.ID); own.Emoji != "" {
+		m.react.cursor = max(slices.Index(reactionChoices, own.Emoji), 0)
+	}
+	m.composer.Blur()
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+}
+
+func (m *model) closeReactionPicker() {
+	m.react = reactionState{}
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+}
+
+func (m model) updateReactionPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	switch key {
+	case "esc":
+		m.closeReactionPicker()
+
+	case "left", "h":
+		m.react.cursor = (m.react.cursor + len(reactionChoices) - 1) % len(reactionChoices)
+
+	case "right", "l":
+		m.react.cursor = (m.react.cursor + 1) % len(reactionChoices)
+
+	case "enter":
+		return m, m.sendReaction(reactionChoices[m.react.cursor])
+
+	case "x", "0", "backspace":
+		return m, m.sendReaction("")
+
+	case "ctrl+c":
+		return m, tea.Quit
+
+	default:
+		if len(key) == 1 && key[0] >= '1' && int(key[0]-'1') < len(reactionChoices) {
+			return m, m.sendReaction(reactionChoices[key[0]-'1'])
+		}
+	}
+
+	return m, nil
+}
+
+// sendReaction puts emoji on the target of the picker; choosing the emoji
+// we already reacted with takes it back, as on the phone.
+func (m *model) sendReaction(emoji string) tea.Cmd {
+	chat, target := m.react.chat, m.react.target
+	m.closeReactionPicker()
+
+	own := m.ownJID()
+	jid, err := types.ParseJID(chat)
+	if err != nil || own == "" {
+		return nil
+	}
+
+	previous := m.ownReaction(chat, target.ID)
+	if previous.Emoji == emoji {
+		emoji = ""
+	}
+	if previous.Emoji == "" && emoji == "" {
+		return nil
+	}
+
+	// The key names the author of the message reacted to.
+	sender := jid
+	switch {
+	case target.FromMe:
+		sender = *m.cli.Store.ID
+	case target.SenderJID != "":
+		if parsed, err := types.ParseJID(target.SenderJID); err == nil {
+			sender = parsed
+		}
+	}
+
+	content := m.cli.BuildReaction(jid, sender, target.ID, emoji)
+	reaction := chatstore.Reaction{
+		MessageID: target.ID,
+		SenderJID: own,
+		Emoji:     emoji,
+		Timestamp: time.UnixMilli(content.GetReactionMessage().GetSenderTimestampMS()),
+	}
+	if previous.SenderJID != "" && previous.SenderJID != own {
+		// Drop a reaction we made under our LID, so we do not count twice.
+		m.setReaction(chat, chatstore.Reaction{MessageID: target.ID, SenderJID: previous.SenderJID, Timestamp: reaction.Timestamp})
+	}
+	m.applyReaction(chat, reaction)
+
+	cli := m.cli
+	return m.dispatchSend(func() tea.Msg {
+		_, err := cli.SendMessage(context.Background(), jid, content)
+		return reactionSentMsg{chat: chat, reaction: reaction, previous: previous, err: err}
+	})
+}
+
+// applyReactionSent stores a sent reaction, or puts back the previous one
+// when sending failed.
+func (m *model) applyReactionSent(msg reactionSentMsg) tea.Cmd {
+	if msg.err == nil {
+		return m.persistReactions(msg.chat, []chatstore.Reaction{msg.reaction})
+	}
+
+	m.pushDevLog(i18n.T("err.react", msg.chat, msg.err))
+
+	ts := msg.reaction.Timestamp
+	m.applyReaction(msg.chat, chatstore.Reaction{MessageID: msg.reaction.MessageID, SenderJID: msg.reaction.SenderJID, Timestamp: ts})
+	if previous := msg.previous; previous.SenderJID != "" {
+		previous.Timestamp = ts
+		m.applyReaction(msg.chat, previous)
+	}
+	return nil
+}
+
+func (m model) reactionPickerView(width int) string {
+	own := m.ownReaction(m.react.chat, m.react.target.ID).Emoji
+
+	choices := make([]string, 0, len(reactionChoices))
+	for i, emoji := range reactionChoices {
+		choice := fmt.Sprintf(" %d %s ", i+1, emoji)
+		switch {
+		case i == m.react.cursor:
+			choice = reactionCursorStyle.Render(choice)
+		case emoji == own:
+			choice = titleStyle.Render(choice)
+		}
+		choices = append(choices, choice)
+	}
+
+	quote := previewLine(m.react.target.Sender+": "+strings.Join(strings.Fields(m.react.target.Body), " "), width-4)
+	return lipgloss.JoinVertical(lipgloss.Left,
+		subtleStyle.Render(i18n.T("react.title", quote)),
+		strings.Join(choices, ""),
+		subtleStyle.Render(i18n.T("react.hint")),
+	)
+}
@@ -0,0 +1,292 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/messageview"
+	tea "github.com/charmbracelet/bubbletea"
+	waWeb "go.mau.fi/whatsmeow/proto/waWeb"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// receiptSavedMsg reports the statuses our messages reached once a receipt
+// was stored.
+type receiptSavedMsg struct {
+	chat     string
+	ids      []string
+	statuses map[string]chatstore.MessageStatus
+}
+
+type readReceiptsSentMsg struct {
+	chat string
+	err  error
+}
+
+// markRoomRead clears the unread count of a room and sends read receipts for
+// its newest incoming messages.
+func (m *model) markRoomRead(jid string) tea.Cmd {
+	room := m.roomList.Room(jid)
+	if room == nil || room.UnreadCount == 0 {
+		return nil
+	}
+
+	return tea.Batch(m.setUnread(jid, 0), m.sendReadReceipts(jid, room.UnreadCount))
+}
+
+// setUnread updates the unread count shown in the room list and stored in
+// the chat store.
+func (m *model) setUnread(jid string, count int) tea.Cmd {
+	room := m.roomList.Room(jid)
+	if room == nil || room.UnreadCount == count {
+		return nil
+	}
+
+	m.roomList = m.roomList.SetUnread(jid, count)
+	if m.store == nil {
+		return nil
+	}
+
+	store := m.store
+	return func() tea.Msg {
+		if err := store.SetUnreadCount(context.Background(), jid, count); err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_chat"), err)}
+		}
+		return nil
+	}
+}
+
+// sendReadReceipts marks up to count of the newest stored incoming messages
+// as read. WhatsApp wants one receipt per sender, which matters in groups.
+func (m model) sendReadReceipts(jid string, count int) tea.Cmd {
+	if m.cli == nil || m.store == nil || count <= 0 {
+		return nil
+	}
+
+	cli, store := m.cli, m.store
+	return func() tea.Msg {
+		chat, err := types.ParseJID(jid)
+		if err != nil {
+			return readReceiptsSentMsg{chat: jid, err: err}
+		}
+
+		messages, err := store.MessagesBefore(context.Background(), jid, chatstore.MessageCursor{}, maxSummaryLines)
+		if err != nil {
+			return readReceiptsSentMsg{chat: jid, err: err}
+		}
+
+		bySender := make(map[types.JID][]types.MessageID)
+		for i := len(messages) - 1; i >= 0 && count > 0; i-- {
+			msg := messages[i]
+			if msg.FromMe {
+				continue
+			}
+			count--
+
+			sender := chat
+			if msg.SenderJID != "" {
+				if parsed, err := types.ParseJID(msg.SenderJID); err == nil {
+					sender = parsed
+				}
+			}
+			bySender[sender] = append(bySender[sender], types.MessageID(msg.ID))
+		}
+
+		now := time.Now()
+		for sender, ids := range bySender {
+			if err := cli.MarkRead(ids, now, chat, sender); err != nil {
+				return readReceiptsSentMsg{chat: jid, err: err}
+			}
+		}
+		return readReceiptsSentMsg{chat: jid}
+	}
+}
+
+// markMessageRead acknowledges a message that arrived in the open room while
+// someone is reading it.
+func (m model) markMessageRead(evt *events.Message) tea.Cmd {
+	if m.cli == nil || evt.Info.IsFromMe || !m.reading(evt.Info.Chat.String()) {
+		return nil
+	}
+
+	cli := m.cli
+	return func() tea.Msg {
+		err := cli.MarkRead([]types.MessageID{evt.Info.ID}, time.Now(), evt.Info.Chat, evt.Info.Sender)
+		return readReceiptsSentMsg{chat: evt.Info.Chat.String(), err: err}
+	}
+}
+
+// roomOpen reports whether the conversation of jid is on screen.
+func (m model) roomOpen(jid string) bool {
+	room := m.roomList.OpenedRoom()
+	return room != nil && room.ID == jid
+}
+
+// reading reports whether the conversation of jid is on screen in a focused
+// terminal with someone at it. Messages arriving otherwise stay unread.
+func (m model) reading(jid string) bool {
+	return m.roomOpen(jid) && m.focused && !m.away
+}
+
+// readOpenRoom marks what arrived in the open room while the terminal was
+// blurred or we were away as read.
+func (m *model) readOpenRoom() tea.Cmd {
+	room := m.roomList.OpenedRoom()
+	if room == nil || !m.reading(room.ID) {
+		return nil
+	}
+	return m.markRoomRead(room.ID)
+}
+
+// applyReceipt raises the status of our own messages when a recipient's
+// device receives, reads or plays them. A group message shows the status
+// every other participant has reached, as the phone does; the info view
+// lists them all.
+func (m *model) applyReceipt(evt *events.Receipt) tea.Cmd {
+	status, ok := receiptStatus(evt.Type)
+	if !ok || evt.IsFromMe || len(evt.MessageIDs) == 0 {
+		return nil
+	}
+
+	chat := evt.Chat.String()
+	group := isGroup(chat)
+	ids := make([]string, 0, len(evt.MessageIDs))
+	for _, id := range evt.MessageIDs {
+		ids = append(ids, string(id))
+		if !group {
+			m.raiseStatus(chat, string(id), status)
+		}
+	}
+	if m.store == nil {
+		return nil
+	}
+
+	store := m.store
+	participant := eventSenderJID(evt.MessageSource).String()
+	at := evt.Timestamp
+	recipients := m.groupRecipients(chat)
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := store.SaveReceipt(ctx, chat, ids, participant, status, at); err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_message"), err)}
+		}
+
+		statuses := make(map[string]chatstore.MessageStatus, len(ids))
+		if group {
+			var err error
+			statuses, err = store.SharedStatus(ctx, chat, ids, recipients)
+			if err != nil {
+				return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_message"), err)}
+			}
+		} else {
+			for _, id := range ids {
+				statuses[id] = status
+			}
+		}
+
+		raised := make(map[chatstore.MessageStatus][]string)
+		for id, reached := range statuses {
+			raised[reached] = append(raised[reached], id)
+		}
+		for reached, changed := range raised {
+			if err := store.UpdateMessageStatus(ctx, chat, changed, reached); err != nil {
+				return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_message"), err)}
+			}
+		}
+
+		return receiptSavedMsg{chat: chat, ids: ids, statuses: statuses}
+	}
+}
+
+// applyReceiptSaved shows the statuses of a stored receipt and reloads the
+// info view, which reads the receipts back from the store.
+func (m *model) applyReceiptSaved(msg receiptSavedMsg) tea.Cmd {
+	for id, status := range msg.statuses {
+		m.raiseStatus(msg.chat, id, status)
+	}
+	return m.reloadMessageInfo(msg.chat, msg.ids)
+}
+
+func (m *model) raiseStatus(chat, id string, status chatstore.MessageStatus) {
+	m.updateChatMessage(chat, id, func(msg *messageview.Message) {
+		if msg.FromMe && msg.Status < viewStatus(status) {
+			msg.Status = viewStatus(status)
+		}
+	})
+}
+
+// groupRecipients counts the other participants of a group, or returns 0
+// while its members are unknown.
+func (m model) groupRecipients(chat string) int {
+	group := m.groups[chat]
+	if group == nil {
+		return 0
+	}
+	return max(len(group.Participants)-1, 0)
+}
+
+func receiptStatus(t types.ReceiptType) (chatstore.MessageStatus, bool) {
+	switch t {
+	case types.ReceiptTypeDelivered:
+		return chatstore.StatusDelivered, true
+	case types.ReceiptTypeRead:
+		return chatstore.StatusRead, true
+	case types.ReceiptTypePlayed:
+		return chatstore.StatusPlayed, true
+	default:
+		return chatstore.StatusUnknown, false
+	}
+}
+
+func webMessageStatus(info *waWeb.WebMessageInfo) chatstore.MessageStatus {
+	if !info.GetKey().GetFromMe() {
+		return chatstore.StatusUnknown
+	}
+
+	switch info.GetStatus() {
+	case waWeb.WebMessageInfo_SERVER_ACK:
+		return chatstore.StatusSent
+	case waWeb.WebMessageInfo_DELIVERY_ACK:
+		return chatstore.StatusDelivered
+	case waWeb.WebMessageInfo_READ:
+		return chatstore.StatusRead
+	case waWeb.WebMessageInfo_PLAYED:
+		return chatstore.StatusPlayed
+	default:
+		return chatstore.StatusUnknown
+	}
+}
+
+func viewStatus(status chatstore.MessageStatus) messageview.Status {
+	switch status {
+	case chatstore.StatusSent:
+		return messageview.StatusSent
+	case chatstore.StatusDelivered:
+		return messageview.StatusDelivered
+	case chatstore.StatusRead:
+		return messageview.StatusRead
+	case chatstore.StatusPlayed:
+		return messageview.StatusPlayed
+	default:
+		return messageview.StatusUnknown
+	}
+}
+
+func storeStatus(status messageview.Status) chatstore.MessageStatus {
+	switch status {
+	case messageview.StatusSent:
+		return chatstore.StatusSent
+	case messageview.StatusDelivered:
+		return chatstore.StatusDelivered
+	case messageview.StatusRead:
+		return chatstore.StatusRead
+	case messageview.StatusPlayed:
+		return chatstore.StatusPlayed
+	default:
+		return chatstore.StatusUnknown
+	}
+}
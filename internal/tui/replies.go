@@ -0,0 +1,217 @@
+package tui
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/media"
+	"github.com/9d4/watui/messageview"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"google.golang.org/protobuf/proto"
+)
+
+// replyState is the message the next composed message replies to.
+type replyState struct {
+	active  bool
+	chat    string
+	message messageview.Message
+}
+
+func (m *model) startReply() {
+	selected := m.messages.Selected()
+	if selected == nil || selected.ID == "" || selected.Pending || selected.Failed || selected.Deleted {
+		return
+	}
+
+	m.reply = replyState{active: true, chat: m.messages.ChatID, message: *selected}
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+}
+
+func (m *model) cancelReply() {
+	m.reply = replyState{}
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+}
+
+// replyTarget returns the message being replied to in the open room.
+func (m model) replyTarget() *messageview.Message {
+	room := m.roomList.OpenedRoom()
+	if !m.reply.active || room == nil || room.ID != m.reply.chat {
+		return nil
+	}
+	return &m.reply.message
+}
+
+// contextInfo returns the reply and mention context of the message types
+// that can carry one.
+func contextInfo(msg *waE2E.Message) *waE2E.ContextInfo {
+	switch {
+	case msg == nil:
+		return nil
+	case msg.GetExtendedTextMessage() != nil:
+		return msg.GetExtendedTextMessage().GetContextInfo()
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetContextInfo()
+	case msg.GetVideoMessage() != nil:
+		return msg.GetVideoMessage().GetContextInfo()
+	case msg.GetAudioMessage() != nil:
+		return msg.GetAudioMessage().GetContextInfo()
+	case msg.GetDocumentMessage() != nil:
+		return msg.GetDocumentMessage().GetContextInfo()
+	case msg.GetStickerMessage() != nil:
+		return msg.GetStickerMessage().GetContextInfo()
+	default:
+		return nil
+	}
+}
+
+// decorateMessage flags attachments and keeps their thumbnail, adds the
+// quoted message of a reply and swaps mentioned phone numbers in the body
+// for contact names.
+func (m *model) decorateMessage(view *messageview.Message, msg *waE2E.Message) {
+	_, view.Media = media.Find(msg)
+	view.Thumbnail = thumbnail(msg)
+
+	info := contextInfo(msg)
+	if info == nil {
+		return
+	}
+
+	view.Body = m.resolveMentions(view.Body, info.GetMentionedJID())
+	view.Mentioned = info.GetMentionedJID()
+	if info.GetStanzaID() == "" || info.GetQuotedMessage() == nil {
+		return
+	}
+
+	quoted := info.GetQuotedMessage()
+	senderJID := info.GetParticipant()
+	if parsed, err := types.ParseJID(senderJID); err == nil && senderJID != "" {
+		senderJID = parsed.ToNonAD().String()
+	}
+
+	view.Quote = &messageview.Quote{
+		ID:        info.GetStanzaID(),
+		Sender:    m.quoteSender(senderJID),
+		SenderJID: senderJID,
+		Body:      m.resolveMentions(summarizeMessage(quoted), contextInfo(quoted).GetMentionedJID()),
+	}
+}
+
+// thumbnail returns the preview embedded in image and sticker messages, so
+// they can be drawn without downloading the file.
+func thumbnail(msg *waE2E.Message) []byte {
+	switch {
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetJPEGThumbnail()
+	case msg.GetStickerMessage() != nil:
+		return msg.GetStickerMessage().GetPngThumbnail()
+	default:
+		return nil
+	}
+}
+
+// decorateStored decodes the raw protobuf kept in the store to decorate a
+// message loaded from it.
+func (m *model) decorateStored(view *messageview.Message, raw []byte) {
+	if len(raw) == 0 {
+		return
+	}
+
+	var msg waE2E.Message
+	if err := proto.Unmarshal(raw, &msg); err != nil {
+		return
+	}
+	m.decorateMessage(view, &msg)
+}
+
+func (m *model) quoteSender(jid string) string {
+	if m.isOwnJID(jid) {
+		return i18n.T("sender.me")
+	}
+	if jid == "" {
+		return i18n.T("sender.unknown")
+	}
+	return m.senderName(jid, "")
+}
+
+func (m model) isOwnJID(jid string) bool {
+	if m.cli == nil || m.cli.Store == nil || jid == "" {
+		return false
+	}
+	if own := m.cli.Store.ID; own != nil && own.ToNonAD().String() == jid {
+		return true
+	}
+	return !m.cli.Store.LID.IsEmpty() && m.cli.Store.LID.ToNonAD().String() == jid
+}
+
+// resolveMentions replaces "@<number>" for every mentioned JID with the
+// name we know the member by. Unknown members keep their number.
+func (m *model) resolveMentions(body string, mentioned []string) string {
+	for _, jid := range mentioned {
+		parsed, err := types.ParseJID(jid)
+		if err != nil || parsed.User == "" {
+			continue
+		}
+		body = strings.ReplaceAll(body, "@"+parsed.User, m.mentionLabel(parsed))
+	}
+	return body
+}
+
+// mentionLabel is the "@Name" resolveMentions shows for a member.
+func (m *model) mentionLabel(jid types.JID) string {
+	name := m.quoteSender(jid.ToNonAD().String())
+	if name == jid.ToNonAD().String() {
+		return "@" + jid.User
+	}
+	return "@" + strings.TrimPrefix(name, "+")
+}
+
+// replyContext builds the context info that quotes target and mentions the
+// given members.
+func (m model) replyContext(chat string, target *messageview.Message, mentioned []string) *waE2E.ContextInfo {
+	if target == nil && len(mentioned) == 0 {
+		return nil
+	}
+
+	info := &waE2E.ContextInfo{}
+	if len(mentioned) > 0 {
+		info.MentionedJID = slices.Clone(mentioned)
+	}
+	if target == nil {
+		return info
+	}
+
+	participant := target.SenderJID
+	if target.FromMe && m.cli != nil && m.cli.Store != nil && m.cli.Store.ID != nil {
+		participant = m.cli.Store.ID.ToNonAD().String()
+	}
+	if participant == "" {
+		participant = chat
+	}
+
+	info.StanzaID = proto.String(target.ID)
+	info.Participant = proto.String(participant)
+	info.QuotedMessage = &waE2E.Message{Conversation: proto.String(target.Body)}
+	return info
+}
+
+// quoteOriginal swaps the text-only quote built from the message view for
+// the stored original, so media replies keep their preview on the phone.
+func (m model) quoteOriginal(chat string, info *waE2E.ContextInfo) {
+	if info == nil || info.GetStanzaID() == "" || m.store == nil {
+		return
+	}
+
+	stored, err := m.store.Message(context.Background(), chat, info.GetStanzaID())
+	if err != nil || stored == nil || len(stored.Raw) == 0 {
+		return
+	}
+
+	var original waE2E.Message
+	if err := proto.Unmarshal(stored.Raw, &original); err != nil {
+		return
+	}
+	info.QuotedMessage = &original
+}
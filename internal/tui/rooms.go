@@ -3,9 +3,13 @@ package tui
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/messageview"
 	"github.com/9d4/watui/roomlist"
 	tea "github.com/charmbracelet/bubbletea"
 	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
@@ -13,13 +17,14 @@ import (
 	waWeb "go.mau.fi/whatsmeow/proto/waWeb"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
 )
 
 const maxSummaryLines = 50
 
-func (m *model) applyHistoryRooms(data *waHistorySync.HistorySync) []roomlist.Room {
+func (m *model) applyHistoryRooms(data *waHistorySync.HistorySync) ([]roomlist.Room, []chatstore.Message, map[string][]chatstore.Reaction) {
 	if data == nil {
-		return nil
+		return nil, nil, nil
 	}
 
 	pushnames := make(map[string]string)
@@ -29,28 +34,43 @@ func (m *model) applyHistoryRooms(data *waHistorySync.HistorySync) []roomlist.Ro
 		}
 		if id := pn.GetID(); id != "" {
 			pushnames[id] = pn.GetPushname()
+			m.rememberPushName(id, pn.GetPushname())
 		}
 	}
 
-	var rooms []roomlist.Room
+	var (
+		rooms     []roomlist.Room
+		stored    []chatstore.Message
+		reactions = make(map[string][]chatstore.Reaction)
+	)
 	for _, conv := range data.GetConversations() {
+		// Reactions go first so the messages are built with them.
+		chatReactions := m.conversationReactions(conv)
+		if parsed, err := types.ParseJID(conv.GetID()); err == nil && len(chatReactions) > 0 {
+			for _, r := range chatReactions {
+				m.setReaction(parsed.String(), r)
+			}
+			reactions[parsed.String()] = chatReactions
+		}
+
 		room, messages := m.roomFromConversation(conv, pushnames)
 		if room == nil {
 			continue
 		}
 
 		rooms = append(rooms, *room)
+		stored = append(stored, conversationRecords(room.ID, conv)...)
 		m.chatTitles[room.ID] = room.Title
 		if len(messages) > 0 {
-			m.storeSummaries(room.ID, messages)
+			m.storeChatMessages(room.ID, messages)
 		}
 		m.roomList = m.roomList.UpsertRoom(*room)
 	}
 
-	return rooms
+	return rooms, stored, reactions
 }
 
-func (m *model) roomFromConversation(conv *waHistorySync.Conversation, pushnames map[string]string) (*roomlist.Room, []string) {
+func (m *model) roomFromConversation(conv *waHistorySync.Conversation, pushnames map[string]string) (*roomlist.Room, []messageview.Message) {
 	if conv == nil {
 		return nil, nil
 	}
@@ -78,13 +98,22 @@ func (m *model) roomFromConversation(conv *waHistorySync.Conversation, pushnames
 		ts = time.Unix(int64(lastTs), 0)
 	}
 
-	return &roomlist.Room{
+	room := &roomlist.Room{
 		ID:          parsed.String(),
 		Title:       title,
 		LastMessage: lastMessage,
 		Time:        ts,
 		UnreadCount: int(conv.GetUnreadCount()),
-	}, conversationMessages(conv)
+		Pinned:      conv.GetPinned() > 0,
+		Archived:    conv.GetArchived(),
+		MutedUntil:  conversationMutedUntil(conv),
+	}
+	// App state events may have come in since the snapshot was taken.
+	if current := m.roomList.Room(room.ID); current != nil {
+		keepChatState(room, *current)
+	}
+
+	return room, m.conversationMessages(parsed.String(), conv)
 }
 
 func (m *model) roomFromMessage(evt *events.Message) *roomlist.Room {
@@ -106,7 +135,21 @@ func (m *model) roomFromMessage(evt *events.Message) *roomlist.Room {
 
 	summary := summarizeMessage(evt.Message)
 	if summary == "" {
-		summary = fmt.Sprintf("Pesan %s", evt.Info.Type)
+		summary = i18n.T("summary.type", evt.Info.Type)
+	}
+
+	current := m.roomList.Room(jid.String())
+
+	// Messages sent from another device mean the chat was read there.
+	unread := 0
+	if !evt.Info.IsFromMe {
+		if current != nil {
+			unread = current.UnreadCount
+		}
+		// Notices such as a changed disappearing timer are not unread.
+		if !m.reading(jid.String()) && evt.Message.GetProtocolMessage() == nil {
+			unread++
+		}
 	}
 
 	room := roomlist.Room{
@@ -114,14 +157,31 @@ func (m *model) roomFromMessage(evt *events.Message) *roomlist.Room {
 		Title:       title,
 		LastMessage: summary,
 		Time:        ts,
+		UnreadCount: unread,
+	}
+	if current != nil {
+		keepChatState(&room, *current)
 	}
 
-	m.appendSummaryLine(room.ID, formatMessageLine(ts, senderFromEvent(evt), summary))
+	msg := messageview.Message{
+		ID:     string(evt.Info.ID),
+		Sender: m.senderFromEvent(evt),
+		Body:   summary,
+		Time:   ts,
+		FromMe: evt.Info.IsFromMe,
+	}
+	if evt.Info.IsFromMe {
+		msg.Status = messageview.StatusSent
+	} else {
+		msg.SenderJID = eventSenderJID(evt.Info.MessageSource).String()
+	}
+	m.decorateMessage(&msg, evt.Message)
+	m.appendChatMessage(room.ID, msg)
 
 	return &room
 }
 
-func (m model) persistHistory(data *waHistorySync.HistorySync, rooms []roomlist.Room) tea.Cmd {
+func (m model) persistHistory(data *waHistorySync.HistorySync, rooms []roomlist.Room, messages []chatstore.Message, reactions map[string][]chatstore.Reaction) tea.Cmd {
 	if m.store == nil || data == nil {
 		return nil
 	}
@@ -141,8 +201,13 @@ func (m model) persistHistory(data *waHistorySync.HistorySync, rooms []roomlist.
 
 	return func() tea.Msg {
 		ctx := context.Background()
-		if err := m.store.PersistHistory(ctx, rooms, state); err != nil {
-			return errMsg{err: fmt.Errorf("gagal menyimpan history: %w", err)}
+		if err := m.store.PersistHistory(ctx, rooms, messages, state); err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_history"), err)}
+		}
+		for chat, chatReactions := range reactions {
+			if err := m.store.SaveReactions(ctx, chat, chatReactions); err != nil {
+				return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_reaction"), err)}
+			}
 		}
 		return nil
 	}
@@ -156,12 +221,194 @@ func (m model) persistRoom(room roomlist.Room) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
 		if err := m.store.UpsertRoom(ctx, room); err != nil {
-			return errMsg{err: fmt.Errorf("gagal menyimpan chat: %w", err)}
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_chat"), err)}
 		}
 		return nil
 	}
 }
 
+func (m model) persistMessage(msg chatstore.Message) tea.Cmd {
+	if m.store == nil || msg.ID == "" || msg.ChatJID == "" {
+		return nil
+	}
+
+	return func() tea.Msg {
+		ctx := context.Background()
+		if err := m.store.SaveMessages(ctx, []chatstore.Message{msg}); err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.save_message"), err)}
+		}
+		return nil
+	}
+}
+
+type roomMessagesLoadedMsg struct {
+	chat      string
+	messages  []chatstore.Message
+	reactions []chatstore.Reaction
+}
+
+func (m model) loadRoomMessages(jid string) tea.Cmd {
+	if m.store == nil || jid == "" {
+		return nil
+	}
+
+	return func() tea.Msg {
+		ctx := context.Background()
+		messages, err := m.store.MessagesBefore(ctx, jid, chatstore.MessageCursor{}, maxSummaryLines)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.load_messages"), err)}
+		}
+		reactions, err := m.store.Reactions(ctx, jid)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.load_messages"), err)}
+		}
+		return roomMessagesLoadedMsg{chat: jid, messages: messages, reactions: reactions}
+	}
+}
+
+func (m *model) applyStoredMessages(jid string, messages []chatstore.Message, reactions []chatstore.Reaction) {
+	m.mergeReactions(jid, reactions)
+	if len(messages) == 0 {
+		return
+	}
+
+	loaded := append(m.viewMessages(messages), m.unsentMessages(jid)...)
+
+	m.storeChatMessages(jid, loaded)
+	if m.messages.ChatID == jid {
+		m.messages = m.messages.SetChat(jid, loaded)
+	}
+}
+
+func (m *model) viewMessages(messages []chatstore.Message) []messageview.Message {
+	out := make([]messageview.Message, 0, len(messages))
+	for _, msg := range messages {
+		out = append(out, m.viewMessage(msg))
+	}
+	return out
+}
+
+func (m *model) viewMessage(msg chatstore.Message) messageview.Message {
+	var sender string
+	switch {
+	case msg.FromMe:
+		sender = i18n.T("sender.me")
+	case msg.SenderJID != "":
+		sender = m.senderName(msg.SenderJID, "")
+	default:
+		sender = i18n.T("sender.unknown")
+	}
+
+	view := messageview.Message{
+		ID:        msg.ID,
+		Sender:    sender,
+		SenderJID: msg.SenderJID,
+		Body:      msg.Body,
+		Time:      msg.Timestamp,
+		FromMe:    msg.FromMe,
+		Status:    viewStatus(msg.Status),
+		Edited:    !msg.EditedAt.IsZero(),
+		Reactions: m.reactionSummary(msg.ChatJID, msg.ID),
+	}
+	if msg.Type == chatstore.TypeRevoked {
+		markDeleted(&view)
+		return view
+	}
+	m.decorateStored(&view, msg.Raw)
+	return view
+}
+
+func storedMessageFromEvent(evt *events.Message) chatstore.Message {
+	status := chatstore.StatusUnknown
+	if evt.Info.IsFromMe {
+		status = chatstore.StatusSent
+	}
+
+	return chatstore.Message{
+		ID:        string(evt.Info.ID),
+		ChatJID:   evt.Info.Chat.String(),
+		SenderJID: eventSenderJID(evt.Info.MessageSource).String(),
+		Timestamp: evt.Info.Timestamp,
+		FromMe:    evt.Info.IsFromMe,
+		Type:      messageType(evt.Message),
+		Body:      summarizeMessage(evt.Message),
+		Raw:       marshalMessage(evt.Message),
+		Status:    status,
+	}
+}
+
+func conversationRecords(chat string, conv *waHistorySync.Conversation) []chatstore.Message {
+	changes := conversationChanges(conv)
+
+	var records []chatstore.Message
+	for _, msg := range conv.GetMessages() {
+		info := msg.GetMessage()
+		if info == nil || info.GetKey().GetID() == "" {
+			continue
+		}
+		// Reactions, edits and revokes change other messages.
+		change, changed := changes[info.GetKey().GetID()]
+		if summarizeMessage(info.GetMessage()) == "" && !change.revoked {
+			continue
+		}
+
+		key := info.GetKey()
+		sender := historySenderJID(info)
+
+		records = append(records, chatstore.Message{
+			ID:        key.GetID(),
+			ChatJID:   chat,
+			SenderJID: sender,
+			Timestamp: time.Unix(int64(info.GetMessageTimestamp()), 0),
+			FromMe:    key.GetFromMe(),
+			Type:      messageType(info.GetMessage()),
+			Body:      summarizeMessage(info.GetMessage()),
+			Raw:       marshalMessage(info.GetMessage()),
+			Status:    webMessageStatus(info),
+		})
+		if changed {
+			change.applyRecord(&records[len(records)-1])
+		}
+	}
+	return records
+}
+
+func marshalMessage(msg *waE2E.Message) []byte {
+	if msg == nil {
+		return nil
+	}
+	raw, err := proto.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+func messageType(msg *waE2E.Message) string {
+	switch {
+	case msg == nil:
+		return ""
+	case msg.GetConversation() != "", msg.GetExtendedTextMessage() != nil:
+		return "text"
+	case msg.GetImageMessage() != nil:
+		return "image"
+	case msg.GetVideoMessage() != nil:
+		return "video"
+	case msg.GetAudioMessage() != nil:
+		return "audio"
+	case msg.GetDocumentMessage() != nil:
+		return "document"
+	case msg.GetStickerMessage() != nil:
+		return "sticker"
+	case msg.GetContactMessage() != nil:
+		return "contact"
+	case msg.GetLocationMessage() != nil, msg.GetLiveLocationMessage() != nil:
+		return "location"
+	default:
+		return "other"
+	}
+}
+
 func conversationSummary(conv *waHistorySync.Conversation) string {
 	msgs := conv.GetMessages()
 	for i := len(msgs) - 1; i >= 0; i-- {
@@ -191,13 +438,14 @@ func summarizeMessage(msg *waE2E.Message) string {
 	case msg.GetExtendedTextMessage() != nil:
 		return msg.GetExtendedTextMessage().GetText()
 	case msg.GetImageMessage() != nil:
-		return "📷 Foto"
+		return withCaption(i18n.T("summary.photo"), msg.GetImageMessage().GetCaption())
 	case msg.GetVideoMessage() != nil:
-		return "🎥 Video"
+		return withCaption(i18n.T("summary.video"), msg.GetVideoMessage().GetCaption())
 	case msg.GetAudioMessage() != nil:
-		return "🎵 Audio"
+		return i18n.T("summary.audio")
 	case msg.GetDocumentMessage() != nil:
-		return fmt.Sprintf("📄 %s", msg.GetDocumentMessage().GetTitle())
+		doc := msg.GetDocumentMessage()
+		return withCaption(i18n.T("summary.document", doc.GetTitle()), doc.GetCaption())
 	case msg.GetButtonsMessage() != nil:
 		return msg.GetButtonsMessage().GetContentText()
 	case msg.GetButtonsResponseMessage() != nil:
@@ -205,109 +453,233 @@ func summarizeMessage(msg *waE2E.Message) string {
 	case msg.GetListResponseMessage() != nil:
 		return msg.GetListResponseMessage().GetTitle()
 	case msg.GetStickerMessage() != nil:
-		return "💠 Stiker"
+		return i18n.T("summary.sticker")
 	case msg.GetContactMessage() != nil:
 		return msg.GetContactMessage().GetDisplayName()
 	case msg.GetLocationMessage() != nil:
 		loc := msg.GetLocationMessage()
-		return fmt.Sprintf("📍 Lokasi %.3f, %.3f", loc.GetDegreesLatitude(), loc.GetDegreesLongitude())
+		return i18n.T("summary.location", loc.GetDegreesLatitude(), loc.GetDegreesLongitude())
 	case msg.GetLiveLocationMessage() != nil:
-		return "📍 Lokasi realtime"
+		return i18n.T("summary.live_location")
 	case msg.GetTemplateButtonReplyMessage() != nil:
 		return msg.GetTemplateButtonReplyMessage().GetSelectedDisplayText()
 	case msg.GetInteractiveResponseMessage() != nil:
 		return msg.GetInteractiveResponseMessage().GetNativeFlowResponseMessage().GetName()
+	case msg.GetReactionMessage() != nil:
+		// Reactions belong to the message they target, not to the chat.
+		return ""
+	case msg.GetProtocolMessage() != nil:
+		return summarizeProtocol(msg.GetProtocolMessage())
 	default:
-		return "Pesan baru"
+		return i18n.T("summary.new")
 	}
 }
 
-func conversationMessages(conv *waHistorySync.Conversation) []string {
-	var lines []string
+// summarizeProtocol describes the protocol messages shown in the chat. The
+// rest, like edits and revokes, change other messages and have no summary.
+func summarizeProtocol(msg *waE2E.ProtocolMessage) string {
+	if msg.Type == nil || msg.GetType() != waE2E.ProtocolMessage_EPHEMERAL_SETTING {
+		return ""
+	}
+
+	expiration := time.Duration(msg.GetEphemeralExpiration()) * time.Second
+	switch {
+	case expiration <= 0:
+		return i18n.T("summary.ephemeral_off")
+	case expiration%(24*time.Hour) == 0 && expiration > 24*time.Hour:
+		return i18n.T("summary.ephemeral_days", int(expiration/(24*time.Hour)))
+	default:
+		return i18n.T("summary.ephemeral_hours", int(expiration.Hours()))
+	}
+}
+
+// withCaption appends the caption of a media message to its label.
+func withCaption(label, caption string) string {
+	if caption = strings.TrimSpace(caption); caption != "" {
+		return label + " " + caption
+	}
+	return label
+}
+
+func (m *model) conversationMessages(chat string, conv *waHistorySync.Conversation) []messageview.Message {
+	changes := conversationChanges(conv)
+
+	var messages []messageview.Message
 	for _, msg := range conv.GetMessages() {
 		if msg == nil {
 			continue
 		}
 
-		line := historyMessageLine(msg.GetMessage())
-		if line != "" {
-			lines = append(lines, line)
+		if item, ok := m.historyViewMessage(chat, msg.GetMessage(), changes); ok {
+			messages = append(messages, item)
 		}
 	}
-	return lines
+
+	slices.SortStableFunc(messages, func(a, b messageview.Message) int {
+		return a.Time.Compare(b.Time)
+	})
+	return messages
 }
 
-func historyMessageLine(info *waWeb.WebMessageInfo) string {
+func (m *model) historyViewMessage(chat string, info *waWeb.WebMessageInfo, changes map[string]messageChange) (messageview.Message, bool) {
 	if info == nil {
-		return ""
+		return messageview.Message{}, false
 	}
 
 	ts := time.Unix(int64(info.GetMessageTimestamp()), 0)
 	body := summarizeMessage(info.GetMessage())
-	if body == "" {
-		return ""
+	change, changed := changes[info.GetKey().GetID()]
+	if body == "" && !change.revoked {
+		return messageview.Message{}, false
 	}
 
+	fromMe := info.GetKey().GetFromMe()
+	senderJID := historySenderJID(info)
+
+	var sender string
+	switch {
+	case fromMe:
+		sender = i18n.T("sender.me")
+	case senderJID != "":
+		sender = m.senderName(senderJID, info.GetPushName())
+	default:
+		sender = i18n.T("sender.unknown")
+	}
+
+	view := messageview.Message{
+		ID:        info.GetKey().GetID(),
+		Sender:    sender,
+		SenderJID: senderJID,
+		Body:      body,
+		Time:      ts,
+		FromMe:    fromMe,
+		Status:    viewStatus(webMessageStatus(info)),
+		Reactions: m.reactionSummary(chat, info.GetKey().GetID()),
+	}
+	m.decorateMessage(&view, info.GetMessage())
+	if changed {
+		m.applyView(change, &view)
+	}
+	return view, true
+}
+
+// historySenderJID returns who sent a history message: the participant in
+// groups and the chat itself in direct chats. Our own messages have none.
+func historySenderJID(info *waWeb.WebMessageInfo) string {
+	key := info.GetKey()
 	sender := info.GetParticipant()
 	if sender == "" {
-		sender = info.GetPushName()
+		sender = key.GetParticipant()
 	}
-	if sender == "" && info.GetKey() != nil {
-		if info.GetKey().GetFromMe() {
-			sender = "Saya"
-		} else {
-			sender = info.GetKey().GetRemoteJID()
-		}
+	if sender == "" && !key.GetFromMe() {
+		sender = key.GetRemoteJID()
 	}
-	if sender == "" {
-		sender = "Unknown"
+	if parsed, err := types.ParseJID(sender); err == nil && sender != "" {
+		return parsed.ToNonAD().String()
 	}
-
-	return formatMessageLine(ts, sender, body)
+	return sender
 }
 
-func senderFromEvent(evt *events.Message) string {
-	if evt.Info.Sender.User != "" || evt.Info.Sender.Server != "" {
-		return evt.Info.Sender.String()
+func (m *model) senderFromEvent(evt *events.Message) string {
+	if evt.Info.IsFromMe {
+		return i18n.T("sender.me")
 	}
-	return evt.Info.Chat.String()
+	return m.senderName(eventSenderJID(evt.Info.MessageSource).String(), evt.Info.PushName)
 }
 
-func formatMessageLine(ts time.Time, sender, body string) string {
-	if body == "" {
-		body = "-"
+// eventSenderJID prefers the phone number JID of a sender, because contact
+// names are keyed by it while groups may address members by LID.
+func eventSenderJID(src types.MessageSource) types.JID {
+	sender := src.Sender
+	if sender.Server == types.HiddenUserServer && !src.SenderAlt.IsEmpty() {
+		sender = src.SenderAlt
 	}
-	if sender == "" {
-		sender = "Unknown"
+	if sender.IsEmpty() {
+		sender = src.Chat
 	}
+	return sender.ToNonAD()
+}
 
-	timeLabel := "-"
-	if !ts.IsZero() {
-		timeLabel = ts.Format("02 Jan 15:04")
+// senderName resolves a sender JID through saved contacts, then the push
+// name that came with the message, then the phone number.
+func (m *model) senderName(jid, pushName string) string {
+	if name := m.contactNames[jid]; name != "" {
+		return name
+	}
+	if name := strings.TrimSpace(pushName); name != "" {
+		return name
 	}
 
-	return fmt.Sprintf("[%s] %s: %s", timeLabel, sender, body)
+	parsed, err := types.ParseJID(jid)
+	if err == nil && parsed.Server == types.DefaultUserServer && parsed.User != "" {
+		return "+" + parsed.User
+	}
+	return jid
 }
 
-func (m *model) storeSummaries(jid string, lines []string) {
-	if len(lines) == 0 {
+// rememberPushName keeps a push name as the sender name of a JID that has
+// no saved contact.
+func (m *model) rememberPushName(jid, pushName string) {
+	pushName = strings.TrimSpace(pushName)
+	if jid == "" || pushName == "" || m.contactNames[jid] != "" {
 		return
 	}
+	m.contactNames[jid] = pushName
+}
 
-	if len(lines) > maxSummaryLines {
-		lines = lines[len(lines)-maxSummaryLines:]
+// refreshSenders renames cached and shown messages after contact names
+// changed.
+func (m *model) refreshSenders() {
+	rename := func(msg *messageview.Message) {
+		if !msg.FromMe && msg.SenderJID != "" {
+			msg.Sender = m.senderName(msg.SenderJID, msg.Sender)
+		}
+		if quote := msg.Quote; quote != nil && quote.SenderJID != "" && !m.isOwnJID(quote.SenderJID) {
+			quote.Sender = m.senderName(quote.SenderJID, quote.Sender)
+		}
 	}
-	m.chatSummaries[jid] = append([]string(nil), lines...)
+
+	for _, messages := range m.chatMessages {
+		for i := range messages {
+			rename(&messages[i])
+		}
+	}
+	m.messages = m.messages.UpdateMessages(rename)
 }
 
-func (m *model) appendSummaryLine(jid, line string) {
-	if line == "" {
+func (m *model) storeChatMessages(jid string, messages []messageview.Message) {
+	if len(messages) == 0 {
 		return
 	}
 
-	lines := append(m.chatSummaries[jid], line)
-	if len(lines) > maxSummaryLines {
-		lines = lines[len(lines)-maxSummaryLines:]
+	if len(messages) > maxSummaryLines {
+		messages = messages[len(messages)-maxSummaryLines:]
+	}
+	m.chatMessages[jid] = append([]messageview.Message(nil), messages...)
+}
+
+func (m *model) appendChatMessage(jid string, msg messageview.Message) {
+	messages := append(m.chatMessages[jid], msg)
+	if len(messages) > maxSummaryLines {
+		messages = messages[len(messages)-maxSummaryLines:]
+	}
+	m.chatMessages[jid] = messages
+
+	if m.messages.ChatID == jid {
+		m.messages = m.messages.Append(msg)
+	}
+}
+
+func (m *model) updateChatMessage(jid, id string, fn func(msg *messageview.Message)) {
+	messages := m.chatMessages[jid]
+	for i := range messages {
+		if messages[i].ID == id {
+			fn(&messages[i])
+			break
+		}
+	}
+
+	if m.messages.ChatID == jid {
+		m.messages = m.messages.UpdateMessage(id, fn)
 	}
-	m.chatSummaries[jid] = lines
 }
@@ -0,0 +1,380 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/messageview"
+	"github.com/9d4/watui/roomlist"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const searchLimit = 50
+
+var (
+	searchSelectedStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212"))
+	searchHighlightStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("0")).Background(lipgloss.Color("11"))
+)
+
+type searchState struct {
+	active  bool
+	input   textinput.Model
+	results []chatstore.SearchResult
+	cursor  int
+	err     error
+
+	// seq drops results of queries the user has already typed past.
+	seq int
+}
+
+type searchResultsMsg struct {
+	seq     int
+	results []chatstore.SearchResult
+	err     error
+}
+
+type searchWindowLoadedMsg struct {
+	chat      string
+	target    chatstore.Message
+	messages  []chatstore.Message
+	reactions []chatstore.Reaction
+	more      bool
+}
+
+type newerMessagesLoadedMsg struct {
+	chat     string
+	messages []chatstore.Message
+}
+
+func newSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = i18n.T("search.placeholder")
+	ti.Prompt = "/ "
+	return ti
+}
+
+func (m *model) openSearch() tea.Cmd {
+	m.search.active = true
+	m.composer.Blur()
+	return m.search.input.Focus()
+}
+
+func (m *model) closeSearch() {
+	m.search.active = false
+	m.search.input.Blur()
+}
+
+func (m model) runSearch(seq int, query string) tea.Cmd {
+	return func() tea.Msg {
+		if m.store == nil {
+			return searchResultsMsg{seq: seq}
+		}
+
+		results, err := m.store.Search(context.Background(), query, searchLimit)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", i18n.T("err.search"), err)
+		}
+		return searchResultsMsg{seq: seq, results: results, err: err}
+	}
+}
+
+func (m *model) applySearchResults(msg searchResultsMsg) {
+	if msg.seq != m.search.seq {
+		return
+	}
+
+	m.search.results = msg.results
+	m.search.err = msg.err
+	m.search.cursor = 0
+}
+
+func (m model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+
+	case "esc":
+		m.closeSearch()
+		return m, nil
+
+	case "up", "ctrl+p":
+		if m.search.cursor > 0 {
+			m.search.cursor--
+		}
+		return m, nil
+
+	case "down", "ctrl+n":
+		if m.search.cursor < len(m.search.results)-1 {
+			m.search.cursor++
+		}
+		return m, nil
+
+	case "enter":
+		if m.search.cursor >= len(m.search.results) {
+			return m, nil
+		}
+		return m, m.jumpToResult(m.search.results[m.search.cursor])
+	}
+
+	before := m.search.input.Value()
+
+	var cmd tea.Cmd
+	m.search.input, cmd = m.search.input.Update(msg)
+
+	query := m.search.input.Value()
+	if query == before {
+		return m, cmd
+	}
+
+	m.search.seq++
+	if strings.TrimSpace(query) == "" {
+		m.search.results = nil
+		m.search.err = nil
+		return m, cmd
+	}
+	return m, tea.Batch(cmd, m.runSearch(m.search.seq, query))
+}
+
+// jumpToResult opens the result's room with the viewport parked on the
+// matching message while the surrounding messages load. As when opening it
+// from the list, a reply, edit or file staged in the room left behind is
+// dropped.
+func (m *model) jumpToResult(result chatstore.SearchResult) tea.Cmd {
+	m.closeSearch()
+	stopTyping := m.stopTyping()
+	m.resetDraft()
+
+	target := result.Message
+	chat := target.ChatJID
+
+	m.roomList = m.roomList.OpenRoom(chat)
+	if room := m.roomList.OpenedRoom(); room == nil || room.ID != chat {
+		m.roomList = m.roomList.UpsertRoom(roomlist.Room{
+			ID:          chat,
+			Title:       m.resolveTitle(chat, ""),
+			LastMessage: target.Body,
+			Time:        target.Timestamp,
+		})
+		m.roomList = m.roomList.OpenRoom(chat)
+	}
+
+	m.messages = m.messages.SetWindow(chat, []messageview.Message{m.viewMessage(target)}, target.ID, true)
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+
+	return tea.Batch(stopTyping, m.loadSearchWindow(target), m.markRoomRead(chat), m.subscribePresence(chat))
+}
+
+func (m model) loadSearchWindow(target chatstore.Message) tea.Cmd {
+	return func() tea.Msg {
+		loaded := searchWindowLoadedMsg{chat: target.ChatJID, target: target}
+		if m.store == nil {
+			return loaded
+		}
+
+		ctx := context.Background()
+		before, err := m.store.MessagesBefore(ctx, target.ChatJID, target.Cursor(), olderPageSize)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.load_messages"), err)}
+		}
+		after, err := m.store.MessagesAfter(ctx, target.ChatJID, target.Cursor(), olderPageSize)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.load_messages"), err)}
+		}
+
+		loaded.reactions, err = m.store.Reactions(ctx, target.ChatJID)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.load_messages"), err)}
+		}
+
+		loaded.messages = append(append(before, target), after...)
+		loaded.more = len(after) == olderPageSize
+		return loaded
+	}
+}
+
+func (m *model) applySearchWindow(msg searchWindowLoadedMsg) {
+	m.mergeReactions(msg.chat, msg.reactions)
+	if m.messages.ChatID != msg.chat || len(msg.messages) == 0 {
+		return
+	}
+
+	loaded := m.viewMessages(msg.messages)
+	if !msg.more {
+		loaded = append(loaded, m.unsentMessages(msg.chat)...)
+	}
+	m.messages = m.messages.SetWindow(msg.chat, loaded, msg.target.ID, msg.more)
+}
+
+// loadNewerMessages pages forwards through the store after a search result
+// opened the conversation away from its newest message.
+func (m model) loadNewerMessages(req messageview.LoadNewerMsg) tea.Cmd {
+	return func() tea.Msg {
+		if m.store == nil || req.After.ID == "" {
+			return newerMessagesLoadedMsg{chat: req.ChatID}
+		}
+
+		cursor := chatstore.MessageCursor{Timestamp: req.After.Time, ID: req.After.ID}
+		messages, err := m.store.MessagesAfter(context.Background(), req.ChatID, cursor, olderPageSize)
+		if err != nil {
+			return errMsg{err: fmt.Errorf("%s: %w", i18n.T("err.load_messages"), err)}
+		}
+		return newerMessagesLoadedMsg{chat: req.ChatID, messages: messages}
+	}
+}
+
+func (m *model) applyNewerMessages(msg newerMessagesLoadedMsg) {
+	if m.messages.ChatID != msg.chat {
+		return
+	}
+
+	more := len(msg.messages) == olderPageSize
+	newer := m.viewMessages(msg.messages)
+	if !more {
+		newer = append(newer, m.unsentMessages(msg.chat)...)
+	}
+	m.messages = m.messages.AppendNewer(newer, more)
+}
+
+// unsentMessages returns the pending and failed sends of a chat, which only
+// live in memory until WhatsApp confirms them.
+func (m model) unsentMessages(jid string) []messageview.Message {
+	var unsent []messageview.Message
+	for _, msg := range m.chatMessages[jid] {
+		if msg.Pending || msg.Failed {
+			unsent = append(unsent, msg)
+		}
+	}
+	return unsent
+}
+
+func (m model) searchView(width, height int) string {
+	width -= 4
+	if width < 1 {
+		width = 1
+	}
+
+	sections := []string{
+		titleStyle.Render(i18n.T("search.title")),
+		m.search.input.View(),
+		"",
+	}
+	footer := []string{"", subtleStyle.Render(i18n.T("search.hint"))}
+
+	switch {
+	case m.search.err != nil:
+		sections = append(sections, lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Width(width).Render(m.search.err.Error()))
+	case strings.TrimSpace(m.search.input.Value()) == "":
+		sections = append(sections, subtleStyle.Render(i18n.T("search.prompt")))
+	case len(m.search.results) == 0:
+		sections = append(sections, subtleStyle.Render(i18n.T("search.empty")))
+	default:
+		// Every result takes two lines plus a blank separator.
+		perPage := (height - len(sections) - len(footer)) / 3
+		if perPage < 1 {
+			perPage = 1
+		}
+		start := 0
+		if m.search.cursor >= perPage {
+			start = m.search.cursor - perPage + 1
+		}
+		end := start + perPage
+		if end > len(m.search.results) {
+			end = len(m.search.results)
+		}
+
+		for i := start; i < end; i++ {
+			if i > start {
+				sections = append(sections, "")
+			}
+			sections = append(sections, m.searchItem(m.search.results[i], width, i == m.search.cursor))
+		}
+	}
+
+	sections = append(sections, footer...)
+	return lipgloss.NewStyle().Padding(0, 2).Render(lipgloss.JoinVertical(lipgloss.Left, sections...))
+}
+
+func (m model) searchItem(result chatstore.SearchResult, width int, selected bool) string {
+	msg := result.Message
+
+	timeLabel := "-"
+	if !msg.Timestamp.IsZero() {
+		timeLabel = msg.Timestamp.Format("02 Jan 15:04")
+	}
+
+	title := m.resolveTitle(msg.ChatJID, "")
+	sender := m.viewMessage(msg).Sender
+
+	prefix := "  "
+	titleRender := titleStyle.Render
+	if selected {
+		prefix = "› "
+		titleRender = searchSelectedStyle.Render
+	}
+
+	header := prefix + titleRender(previewLine(title, width/2)) +
+		subtleStyle.Render(fmt.Sprintf(" · %s · %s", sender, timeLabel))
+	snippet := "  " + renderSnippet(result.Snippet, width-2)
+
+	return header + "\n" + snippet
+}
+
+// renderSnippet flattens a search snippet to one line of at most width
+// runes and styles the parts wrapped in chatstore highlight markers.
+func renderSnippet(snippet string, width int) string {
+	snippet = strings.Join(strings.Fields(snippet), " ")
+
+	var (
+		b           strings.Builder
+		part        strings.Builder
+		highlighted bool
+		count       int
+	)
+	flush := func() {
+		if part.Len() == 0 {
+			return
+		}
+		if highlighted {
+			b.WriteString(searchHighlightStyle.Render(part.String()))
+		} else {
+			b.WriteString(part.String())
+		}
+		part.Reset()
+	}
+
+	for _, r := range snippet {
+		switch string(r) {
+		case chatstore.HighlightStart:
+			flush()
+			highlighted = true
+			continue
+		case chatstore.HighlightEnd:
+			flush()
+			highlighted = false
+			continue
+		}
+
+		if width > 0 && count >= width-1 {
+			part.WriteString("…")
+			break
+		}
+		part.WriteRune(r)
+		count++
+	}
+	flush()
+
+	return b.String()
+}
+
+func previewLine(text string, width int) string {
+	runes := []rune(text)
+	if width <= 1 || len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/wa"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ManagerOpener opens the WhatsApp session store. It is retried from the
+// error screen, so it must be safe to call more than once.
+type ManagerOpener func() (*wa.Manager, error)
+
+type managerReadyMsg struct {
+	manager *wa.Manager
+}
+
+func (m model) openManager() tea.Cmd {
+	return func() tea.Msg {
+		if m.managerOpener == nil {
+			return errMsg{err: errors.New(i18n.T("err.session_missing"))}
+		}
+
+		manager, err := m.managerOpener()
+		if err != nil {
+			return errMsg{
+				err:   fmt.Errorf("%s: %w", i18n.T("err.open_session"), err),
+				hint:  sessionErrorHint(err),
+				retry: m.openManager(),
+			}
+		}
+		return managerReadyMsg{manager: manager}
+	}
+}
+
+func sessionErrorHint(err error) string {
+	switch {
+	case errors.Is(err, wa.ErrSessionLocked):
+		return i18n.T("hint.session_locked")
+	case errors.Is(err, wa.ErrSessionReadOnly):
+		return i18n.T("hint.session_readonly")
+	case errors.Is(err, wa.ErrSessionCorrupt):
+		return i18n.T("hint.session_corrupt")
+	case errors.Is(err, wa.ErrSessionTooNew):
+		return i18n.T("hint.session_too_new")
+	case errors.Is(err, wa.ErrSessionUpgrade):
+		return i18n.T("hint.session_upgrade")
+	default:
+		return i18n.T("hint.session_default")
+	}
+}
+
+// restart drops the current client and runs the startup sequence again.
+func (m *model) restart() tea.Cmd {
+	m.switchClient()
+	m.state = stateLoading
+	m.statusMessage = i18n.T("status.preparing_wa")
+	m.errorHint = ""
+
+	if m.wa == nil {
+		return m.openManager()
+	}
+	return m.loadAccounts()
+}
+
+func (m model) updateError(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+
+	case "r", "enter":
+		retry := m.retry
+		m.retry = nil
+		if retry == nil {
+			return m, m.restart()
+		}
+
+		m.state = stateLoading
+		m.statusMessage = i18n.T("status.retrying")
+		m.errorHint = ""
+		return m, retry
+	}
+
+	return m, nil
+}
@@ -1,13 +1,16 @@
 package tui
 
 import (
-	"context"
 	"fmt"
 	"strings"
 
+	"github.com/9d4/watui/chatstore"
+	"github.com/9d4/watui/i18n"
+	"github.com/9d4/watui/messageview"
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"go.mau.fi/whatsmeow"
+	waHistorySync "go.mau.fi/whatsmeow/proto/waHistorySync"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
@@ -24,15 +27,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// Any key brings us back from being away. Key handlers return early,
+	// so the presence update is batched around the whole update.
+	if _, ok := msg.(tea.KeyMsg); ok {
+		if back := m.noteInput(); back != nil {
+			next, cmd := m.Update(msg)
+			return next, tea.Batch(back, cmd)
+		}
+	}
+
 	switch msg := msg.(type) {
 	case roomsLoadedMsg:
 		if len(msg.rooms) > 0 {
 			m.roomList = m.roomList.ReplaceRooms(msg.rooms)
 			for _, room := range msg.rooms {
 				m.chatTitles[room.ID] = room.Title
-				if room.LastMessage != "" && m.chatSummaries[room.ID] == nil {
-					line := formatMessageLine(room.Time, "Terakhir", room.LastMessage)
-					m.chatSummaries[room.ID] = []string{line}
+				if room.LastMessage != "" && m.chatMessages[room.ID] == nil {
+					m.chatMessages[room.ID] = []messageview.Message{{
+						Sender: i18n.T("sender.last"),
+						Body:   room.LastMessage,
+						Time:   room.Time,
+					}}
 				}
 			}
 		}
@@ -46,7 +61,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.state == stateLoading {
 				m.state = stateHistorySync
 			}
-			m.historyMessage = fmt.Sprintf("Melanjutkan sinkronisasi · %d%%", msg.sync.Progress)
+			m.historyMessage = i18n.T("sync.resuming", msg.sync.Progress)
 		} else if msg.sync.Progress >= 100 {
 			m.historyReady = true
 			if m.state == stateLoading {
@@ -57,7 +72,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.state == stateChats && msg.sync.InProgress {
 			m.syncOverlay = syncOverlayState{
 				active: true,
-				label:  fmt.Sprintf("Sinkronisasi lanjutan · %d%%", msg.sync.Progress),
+				label:  i18n.T("sync.continued", msg.sync.Progress),
 			}
 		}
 
@@ -65,35 +80,78 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		for jid, name := range msg.names {
 			m.applyContactName(jid, name)
 		}
+		m.refreshSenders()
+
+	case joinedGroupsMsg:
+		if msg.err != nil {
+			m.logGroupError("", msg.err)
+		}
+		for _, info := range msg.groups {
+			appendCmd(m.applyGroupInfo(info))
+		}
+
+	case groupInfoMsg:
+		if msg.err != nil {
+			m.logGroupError(msg.jid, msg.err)
+			break
+		}
+		appendCmd(m.applyGroupInfo(msg.info))
+
+	case managerReadyMsg:
+		m.wa = msg.manager
+		appendCmd(m.loadAccounts())
+
+	case accountsLoadedMsg:
+		appendCmd(m.applyAccounts(msg.accounts))
 
 	case clientReadyMsg:
+		if m.cli != nil && m.cli != msg.cli {
+			m.switchClient()
+		}
+
 		m.cli = msg.cli
+		m.store = msg.store
 		if m.cli == nil {
 			m.state = stateError
-			m.statusMessage = "client tidak tersedia"
+			m.statusMessage = i18n.T("err.client_unavailable")
 			break
 		}
 
 		m.cli.AddEventHandler(func(evt any) {
 			m.events <- waEvent{evt}
 		})
+		m.watchReconnects(m.cli)
+		// Pins, mutes and archives only come as events when a full sync
+		// emits them too.
+		m.cli.EmitAppStateEventsOnFullSync = true
 
+		appendCmd(m.loadStoredRooms())
 		appendCmd(m.loadContacts())
 		appendCmd(m.syncContactsAppState())
 
 		if m.cli.Store.ID == nil {
 			m.state = stateWelcome
-			m.statusMessage = "Tekan Enter untuk mulai pairing"
+			m.statusMessage = i18n.T("status.press_enter")
 			m.historyReady = false
 		} else {
 			m.state = stateConnecting
-			m.statusMessage = "Menghubungkan ke WhatsApp..."
+			m.statusMessage = i18n.T("status.connecting_wa")
 			m.historyReady = true
 			appendCmd(m.connectClient())
 		}
 
 	case qrCodeMsg:
 		m.waQRCode = msg.Code
+		m.pairCode = ""
+		m.state = statePairing
+		m.qrStatus = ""
+		m.historyReady = false
+		m.syncOverlay = syncOverlayState{}
+		appendCmd(m.waitEvents())
+
+	case pairCodeMsg:
+		m.pairCode = msg.Code
+		m.waQRCode = ""
 		m.state = statePairing
 		m.qrStatus = ""
 		m.historyReady = false
@@ -105,41 +163,52 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg.Status {
 		case whatsmeow.QRChannelSuccess.Event:
-			m.qrStatus = "QR berhasil dipindai, menunggu sinkronisasi"
+			m.qrStatus = i18n.T("qr.success")
 			m.waQRCode = ""
+			m.pairCode = ""
 			m.state = stateHistorySync
-			m.historyMessage = "Menunggu history dari WhatsApp..."
+			m.historyMessage = i18n.T("sync.waiting_wa")
 			m.historyReady = false
 			m.syncOverlay = syncOverlayState{}
 			appendCmd(m.syncProgress.SetPercent(0))
 		case whatsmeow.QRChannelTimeout.Event:
-			m.qrStatus = "Sesi pairing habis, coba ulangi"
+			m.qrStatus = i18n.T("qr.timeout")
 			m.state = stateWelcome
 		case whatsmeow.QRChannelErrUnexpectedEvent.Event:
-			m.qrStatus = "Status pairing tidak terduga, coba ulangi"
+			m.qrStatus = i18n.T("qr.unexpected")
 			m.state = stateWelcome
 		case whatsmeow.QRChannelClientOutdated.Event:
-			m.qrStatus = "Client kedaluwarsa, update whatsmeow"
+			m.qrStatus = i18n.T("qr.outdated")
 			m.state = stateWelcome
 		case whatsmeow.QRChannelScannedWithoutMultidevice.Event:
-			m.qrStatus = "Aktifkan multi-device di HP terlebih dahulu"
+			m.qrStatus = i18n.T("qr.multidevice")
 			m.state = stateWelcome
 		case whatsmeow.QRChannelEventError:
-			m.qrStatus = fmt.Sprintf("Pairing gagal: %v", msg.Err)
+			m.qrStatus = i18n.T("qr.failed", msg.Err)
 			m.state = stateWelcome
 		default:
-			m.qrStatus = fmt.Sprintf("Status pairing: %s", msg.Status)
+			m.qrStatus = i18n.T("qr.status", msg.Status)
 		}
 
 		if m.state == stateWelcome {
+			m.pairCode = ""
 			m.syncOverlay = syncOverlayState{}
 		}
 
 	case waEvent:
 		appendCmd(m.waitEvents())
 
+		if cmd, ok := m.applyConnectionEvent(msg.evt); ok {
+			appendCmd(cmd)
+		}
+		if cmd, ok := m.applyChatStateEvent(msg.evt); ok {
+			appendCmd(cmd)
+		}
+
 		switch evt := msg.evt.(type) {
 		case *events.Connected:
+			appendCmd(m.loadJoinedGroups())
+			appendCmd(m.resubscribePresence())
 			if m.historyReady {
 				m.state = stateChats
 				m.statusMessage = ""
@@ -147,17 +216,27 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateHistorySync
 			}
 
-		case *events.LoggedOut:
-			return m, func() tea.Msg {
-				if m.cli != nil {
-					_ = m.cli.Logout(context.Background())
+		case *events.PairSuccess:
+			if m.store == nil {
+				store, err := m.openStore(evt.ID)
+				if err != nil {
+					m.pushDevLog(fmt.Sprintf("%s: %v", i18n.T("err.open_chat_data"), err))
 				}
-				return tea.Quit()
+				m.store = store
 			}
 
+		case *events.LoggedOut:
+			// whatsmeow already removed the device from the session store.
+			m.enterLoggedOut(logoutReason(evt))
+
 		case *events.HistorySync:
+			if evt.Data.GetSyncType() == waHistorySync.HistorySync_ON_DEMAND {
+				appendCmd(m.applyOnDemandHistory(evt.Data))
+				break
+			}
+
 			if evt.Data != nil {
-				rooms := m.applyHistoryRooms(evt.Data)
+				rooms, messages, reactions := m.applyHistoryRooms(evt.Data)
 
 				progress := float64(evt.Data.GetProgress()) / 100
 				if progress > 1 {
@@ -165,17 +244,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				appendCmd(m.syncProgress.SetPercent(progress))
-				appendCmd(m.persistHistory(evt.Data, rooms))
+				appendCmd(m.persistHistory(evt.Data, rooms, messages, reactions))
 
 				var syncLabel string
 				if evt.Data.SyncType != nil {
 					syncLabel = strings.ToLower(evt.Data.GetSyncType().String())
 				} else {
-					syncLabel = "history"
+					syncLabel = i18n.T("sync.default_kind")
 				}
 
-				info := fmt.Sprintf(
-					"Sinkronisasi %s · %d chat",
+				info := i18n.T(
+					"sync.info",
 					syncLabel,
 					len(evt.Data.GetConversations()),
 				)
@@ -203,10 +282,22 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case *events.Message:
-			if room := m.roomFromMessage(evt); room != nil {
+			if !evt.Info.IsFromMe {
+				m.rememberPushName(eventSenderJID(evt.Info.MessageSource).String(), evt.Info.PushName)
+			}
+			if reaction, ok := reactionFromEvent(evt); ok {
+				// A reaction updates its message and leaves the room as is.
+				m.applyReaction(evt.Info.Chat.String(), reaction)
+				appendCmd(m.persistReactions(evt.Info.Chat.String(), []chatstore.Reaction{reaction}))
+			} else if cmd, ok := m.applyProtocolMessage(evt); ok {
+				appendCmd(cmd)
+			} else if room := m.roomFromMessage(evt); room != nil {
 				m.roomList = m.roomList.UpsertRoom(*room)
 				m.chatTitles[room.ID] = room.Title
 				appendCmd(m.persistRoom(*room))
+				appendCmd(m.persistMessage(storedMessageFromEvent(evt)))
+				appendCmd(m.markMessageRead(evt))
+				appendCmd(m.notifyMessage(evt, *room))
 			}
 
 			m.pushDevLog(fmt.Sprintf(
@@ -216,6 +307,19 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				evt.Info.Type,
 			))
 
+		case *events.Receipt:
+			if evt.Type == types.ReceiptTypeReadSelf {
+				appendCmd(m.setUnread(evt.Chat.String(), 0))
+			}
+			appendCmd(m.applyReceipt(evt))
+
+		case *events.MarkChatAsRead:
+			if evt.Action.GetRead() {
+				appendCmd(m.setUnread(evt.JID.String(), 0))
+			} else if room := m.roomList.Room(evt.JID.String()); room != nil && room.UnreadCount == 0 {
+				appendCmd(m.setUnread(evt.JID.String(), 1))
+			}
+
 		case *events.Contact:
 			if evt.Action != nil {
 				info := types.ContactInfo{
@@ -224,49 +328,241 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				name := resolveContactName(info, evt.JID.String())
 				m.applyContactName(evt.JID.String(), name)
+				m.refreshSenders()
+			}
+
+		case *events.GroupInfo:
+			if evt.Name != nil {
+				appendCmd(m.renameGroup(evt.JID.String(), evt.Name.Name))
 			}
+			appendCmd(m.loadGroupInfo(evt.JID.String()))
+
+		case *events.JoinedGroup:
+			appendCmd(m.applyGroupInfo(&evt.GroupInfo))
+
+		case *events.ChatPresence:
+			appendCmd(m.applyChatPresence(evt))
+
+		case *events.Presence:
+			m.applyPresence(evt)
 
 		case *events.PushName:
 			name := strings.TrimSpace(evt.NewPushName)
 			if name != "" {
 				m.applyContactName(evt.JID.String(), name)
+				m.refreshSenders()
+			}
+		}
+
+	case roomMessagesLoadedMsg:
+		m.applyStoredMessages(msg.chat, msg.messages, msg.reactions)
+
+	case searchResultsMsg:
+		m.applySearchResults(msg)
+
+	case searchWindowLoadedMsg:
+		m.applySearchWindow(msg)
+
+	case messageview.LoadNewerMsg:
+		appendCmd(m.loadNewerMessages(msg))
+
+	case newerMessagesLoadedMsg:
+		m.applyNewerMessages(msg)
+
+	case messageview.LoadOlderMsg:
+		appendCmd(m.loadOlderMessages(msg))
+
+	case olderMessagesLoadedMsg:
+		appendCmd(m.applyOlderMessages(msg))
+
+	case historyRequestedMsg:
+		if msg.err != nil {
+			if m.messages.ChatID == msg.chat {
+				m.messages = m.messages.StopLoading()
 			}
+			m.pushDevLog(i18n.T("err.request_history", msg.chat, msg.err))
 		}
 
+	case messageInfoLoadedMsg:
+		m.applyMessageInfo(msg)
+
+	case receiptSavedMsg:
+		appendCmd(m.applyReceiptSaved(msg))
+
+	case readReceiptsSentMsg:
+		if msg.err != nil {
+			m.pushDevLog(i18n.T("err.mark_read", msg.chat, msg.err))
+		}
+
+	case messageSentMsg:
+		appendCmd(m.resolvePendingSend(msg))
+
+	case reactionSentMsg:
+		appendCmd(m.applyReactionSent(msg))
+
+	case editSentMsg:
+		appendCmd(m.applyEditSent(msg))
+
+	case revokeSentMsg:
+		appendCmd(m.applyRevokeSent(msg))
+
+	case chatStateSentMsg:
+		appendCmd(m.applyChatStateSent(msg))
+
+	case mediaDoneMsg:
+		m.applyMediaDone(msg)
+
+	case uploadProgressMsg:
+		appendCmd(m.applyUploadProgress(msg))
+		appendCmd(m.waitEvents())
+
+	case reconnectFailedMsg:
+		appendCmd(m.applyReconnectFailed(msg))
+		appendCmd(m.waitEvents())
+
+	case presenceExpiredMsg:
+		m.expireTyping()
+
+	case typingIdleMsg:
+		appendCmd(m.applyTypingIdle(msg))
+
+	case chatPresenceSentMsg:
+		m.applyChatPresenceSent(msg)
+
+	case loggedOutMsg:
+		m.enterLoggedOut(i18n.T("logout.reason_manual"))
+
+	case chatDataClearedMsg:
+		m.applyChatDataCleared(msg)
+
+	case reconnectResultMsg:
+		m.applyReconnectResult(msg)
+
+	case banExpiredMsg:
+		appendCmd(m.applyBanExpired(msg))
+
+	case connTickMsg:
+		appendCmd(m.applyConnTick(msg))
+
 	case errMsg:
 		m.state = stateError
 		m.statusMessage = msg.Error()
+		m.errorHint = msg.hint
+		m.retry = msg.retry
+
+	case notifySentMsg:
+		m.applyNotifySent(msg)
+
+	case tea.BlurMsg:
+		appendCmd(m.applyFocus(false))
 
 	case tea.FocusMsg:
-		if m.cli != nil && m.state == stateChats {
-			m.cli.SendPresence(types.PresenceAvailable)
-		}
+		appendCmd(m.applyFocus(true))
+
+	case idleCheckMsg:
+		appendCmd(m.applyIdleCheck())
 
 	case tea.WindowSizeMsg:
 		m.width = msg.Width - 2
 		m.height = msg.Height - 2
 		m.roomList = m.roomList.SetViewportHeight(m.contentHeight())
+		m.messages = m.messages.SetSize(m.messagePaneSize())
 
 	case tea.KeyMsg:
+		if m.quit.confirming {
+			return m.updateQuitConfirm(msg)
+		}
+		if m.holdQuit(msg) {
+			return m, nil
+		}
+		if m.composing() {
+			return m.updateComposer(msg)
+		}
+		if m.logout.confirming {
+			return m.updateLogoutConfirm(msg)
+		}
+		if m.search.active && m.state == stateChats {
+			return m.updateSearch(msg)
+		}
+		if m.messageInfo.active && m.state == stateChats {
+			return m.updateMessageInfo(msg)
+		}
+		if m.media.saving && m.state == stateChats {
+			return m.updateSavePrompt(msg)
+		}
+		if m.attach.picking && m.state == stateChats {
+			return m.updateFilePicker(msg)
+		}
+		if m.react.picking && m.state == stateChats {
+			return m.updateReactionPicker(msg)
+		}
+		if m.revoke.confirming && m.state == stateChats {
+			return m.updateRevokeConfirm(msg)
+		}
+		if m.roomList.Filtering() && m.state == stateChats {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return m, m.updateChats(msg)
+		}
+		if m.state == stateAccounts {
+			return m.updateAccounts(msg)
+		}
+		if m.state == statePhoneInput {
+			return m.updatePhoneInput(msg)
+		}
+		if m.state == stateError {
+			return m.updateError(msg)
+		}
+		if m.state == stateLoggedOut {
+			return m.updateLoggedOut(msg)
+		}
+
 		key := msg.String()
 		switch key {
 		case "q", "ctrl+c":
+			if len(m.conn.outbox) > 0 {
+				m.confirmQuit()
+				break
+			}
 			return m, tea.Quit
 
 		case "ctrl+q":
-			if m.cli == nil {
-				break
+			m.confirmLogout()
+
+		case "p":
+			if m.state == stateWelcome {
+				m.state = statePhoneInput
+				m.qrStatus = ""
+				appendCmd(m.phoneInput.Focus())
 			}
 
-			return m, func() tea.Msg {
-				_ = m.cli.Logout(context.Background())
-				return tea.Quit()
+		case "/":
+			// In the room list "/" filters rooms; inside a conversation it
+			// searches messages.
+			if m.state == stateChats && m.roomList.OpenedRoom() != nil {
+				return m, m.openSearch()
+			}
+
+		case "ctrl+s":
+			if m.state == stateChats {
+				return m, m.openSearch()
+			}
+
+		case "ctrl+r":
+			if m.state == stateChats {
+				appendCmd(m.reconnect())
+			}
+
+		case "ctrl+a":
+			if m.state == stateChats || m.state == stateWelcome {
+				appendCmd(m.loadAccounts())
 			}
 
 		case "enter":
 			if m.state == stateWelcome {
 				m.state = statePairing
-				m.statusMessage = "Menghubungkan..."
+				m.statusMessage = i18n.T("status.connecting")
 				m.qrStatus = ""
 				m.historyReady = false
 				appendCmd(m.startPairing())
@@ -276,7 +572,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch m.state {
 	case stateChats:
-		m.roomList, cmd = m.roomList.Update(msg)
+		appendCmd(m.updateChats(msg))
+	case statePhoneInput:
+		m.phoneInput, cmd = m.phoneInput.Update(msg)
 		appendCmd(cmd)
 	default:
 		m.loading, cmd = m.loading.Update(msg)
@@ -290,5 +588,114 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 	appendCmd(cmd)
 
+	progressModel, cmd = m.upload.bar.Update(msg)
+	if prog, ok := progressModel.(progress.Model); ok {
+		m.upload.bar = prog
+	}
+	appendCmd(cmd)
+
 	return m, tea.Batch(cmds...)
 }
+
+func (m *model) updateChats(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+
+	key, isKey := msg.(tea.KeyMsg)
+	if !isKey {
+		if m.search.active {
+			m.search.input, cmd = m.search.input.Update(msg)
+		} else if m.media.saving {
+			m.media.input, cmd = m.media.input.Update(msg)
+		} else if m.attach.picking {
+			m.attach.picker, cmd = m.attach.picker.Update(msg)
+		} else if m.roomList.Filtering() {
+			m.roomList, cmd = m.roomList.Update(msg)
+		} else if m.composer.Focused() {
+			m.composer, cmd = m.composer.Update(msg)
+		}
+		return cmd
+	}
+
+	if m.roomList.OpenedRoom() == nil {
+		if !m.roomList.Filtering() {
+			switch key.String() {
+			case "p":
+				return m.togglePin()
+			case "m":
+				return m.toggleMute()
+			case "a":
+				return m.toggleArchive()
+			}
+		}
+
+		m.roomList, cmd = m.roomList.Update(msg)
+		if key.String() == "enter" {
+			if room := m.roomList.OpenedRoom(); room != nil {
+				return tea.Batch(cmd, m.openRoom(room.ID))
+			}
+		}
+		return cmd
+	}
+
+	// A download result stays up until the next key.
+	m.media.notice = ""
+
+	switch key.String() {
+	case "esc":
+		m.roomList, cmd = m.roomList.Update(msg)
+		m.messages = m.messages.SetChat("", nil)
+		m.resetDraft()
+		return cmd
+
+	case "i":
+		return m.composer.Focus()
+
+	case "r":
+		m.startReply()
+		if !m.reply.active {
+			return nil
+		}
+		return m.composer.Focus()
+
+	case "o":
+		return m.openMedia()
+
+	case "s":
+		return m.startSaveMedia()
+
+	case "a":
+		return m.openFilePicker()
+
+	case "I":
+		return m.openMessageInfo()
+
+	case "e":
+		m.openReactionPicker()
+		return nil
+
+	case "E":
+		return m.startEdit()
+
+	case "D":
+		m.startRevoke()
+		return nil
+
+	case "ctrl+g":
+		m.toggleGroupPanel()
+		return nil
+	}
+
+	m.messages, cmd = m.messages.Update(msg)
+	return cmd
+}
+
+func (m *model) openRoom(jid string) tea.Cmd {
+	m.resetDraft()
+	m.messages = m.messages.SetChat(jid, m.chatMessages[jid])
+	m.messages = m.messages.SetSize(m.messagePaneSize())
+	cmds := []tea.Cmd{m.composer.Focus(), m.loadRoomMessages(jid), m.markRoomRead(jid), m.subscribePresence(jid)}
+	if m.groups[jid] == nil {
+		cmds = append(cmds, m.loadGroupInfo(jid))
+	}
+	return tea.Batch(cmds...)
+}
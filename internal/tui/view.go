@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/9d4/watui/i18n"
 	"github.com/9d4/watui/roomlist"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mdp/qrterminal/v3"
@@ -37,6 +38,14 @@ var (
 			BorderStyle(lipgloss.RoundedBorder()).
 			Foreground(lipgloss.Color("253")).
 			Background(lipgloss.Color("60"))
+	composerStyle = lipgloss.NewStyle().
+			BorderStyle(lipgloss.NormalBorder()).
+			BorderTop(true)
+	pairCodeStyle = lipgloss.NewStyle().
+			Bold(true).
+			Padding(1, 4).
+			BorderStyle(lipgloss.ThickBorder()).
+			BorderForeground(lipgloss.Color("10"))
 	syncOverlayStyle = lipgloss.NewStyle().
 				Padding(0, 1).
 				BorderStyle(lipgloss.NormalBorder()).
@@ -67,9 +76,18 @@ func (m model) View() string {
 	case statePairing:
 		baseContent = m.pairingView()
 
+	case statePhoneInput:
+		baseContent = m.phoneInputView()
+
 	case stateHistorySync:
 		baseContent = m.historySyncView()
 
+	case stateAccounts:
+		baseContent = m.accountsView()
+
+	case stateLoggedOut:
+		baseContent = m.loggedOutView()
+
 	default:
 		baseContent = m.loadingStatusView()
 	}
@@ -106,6 +124,25 @@ func (m model) View() string {
 		mainAlignH = lipgloss.Left
 		mainAlignV = lipgloss.Top
 		mainContent = m.chatLayout(innerWidth, mainHeight)
+		switch {
+		case m.search.active:
+			mainContent = m.searchView(innerWidth, mainHeight)
+		case m.messageInfo.active:
+			mainContent = m.messageInfoView(innerWidth)
+		case m.attach.picking:
+			mainContent = m.filePickerView(innerWidth)
+		}
+	}
+
+	if m.logout.confirming {
+		mainContent = m.logoutConfirmView()
+		mainAlignH = lipgloss.Center
+		mainAlignV = lipgloss.Center
+	}
+	if m.quit.confirming {
+		mainContent = m.quitConfirmView()
+		mainAlignH = lipgloss.Center
+		mainAlignV = lipgloss.Center
 	}
 
 	sections = append(sections,
@@ -138,17 +175,30 @@ func (m model) View() string {
 func (m model) loadingStatusView() string {
 	status := m.statusMessage
 	if status == "" {
-		status = "Menyiapkan..."
+		status = i18n.T("status.preparing")
 	}
 
 	return fmt.Sprintf("%s %s", m.loading.View(), status)
 }
 
 func (m model) errorView() string {
-	if m.statusMessage == "" {
-		return "Terjadi kesalahan yang tidak diketahui."
+	message := m.statusMessage
+	if message == "" {
+		message = i18n.T("err.unknown")
+	}
+
+	width := m.contentWidth()
+	if width > 72 {
+		width = 72
+	}
+
+	sections := []string{lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Width(width).Render(message)}
+	if m.errorHint != "" {
+		sections = append(sections, "", lipgloss.NewStyle().Width(width).Render(m.errorHint))
 	}
-	return lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Render(m.statusMessage)
+	sections = append(sections, "", subtleStyle.Render(i18n.T("error.keys")))
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
 }
 
 func (m model) welcomeView() string {
@@ -160,11 +210,12 @@ func (m model) welcomeView() string {
 		AlignHorizontal(lipgloss.Center).
 		AlignVertical(lipgloss.Center).
 		Render("watui")
-	desc := "Connect WhatsAppmu langsung dari terminal tanpa ribet."
-	button := buttonStyle.Render(" Continue ")
-	hint := "Tekan Enter untuk melanjutkan."
+	desc := i18n.T("welcome.desc")
+	button := buttonStyle.Render(i18n.T("welcome.button"))
+	hint := i18n.T("welcome.hint")
+	altHint := subtleStyle.Render(i18n.T("welcome.alt_hint"))
 
-	sections = append(sections, title, desc, "", button, hint)
+	sections = append(sections, title, desc, "", button, hint, altHint)
 
 	if m.qrStatus != "" {
 		sections = append(sections, "", subtleStyle.Render(m.qrStatus))
@@ -174,13 +225,16 @@ func (m model) welcomeView() string {
 }
 
 func (m model) pairingView() string {
+	if m.pairCode != "" {
+		return m.pairCodeView()
+	}
 	if m.waQRCode == "" {
 		return m.loadingStatusView()
 	}
 
 	var builder strings.Builder
 	builder.WriteString(m.loading.View())
-	builder.WriteString(" Scan kode di bawah menggunakan WhatsApp\n\n")
+	builder.WriteString(" " + i18n.T("pair.scan_qr") + "\n\n")
 	qrterminal.GenerateHalfBlock(m.waQRCode, qrterminal.L, &builder)
 
 	if m.qrStatus != "" {
@@ -193,11 +247,11 @@ func (m model) pairingView() string {
 func (m model) historySyncView() string {
 	var builder strings.Builder
 	builder.WriteString(m.loading.View())
-	builder.WriteString(" Sinkronisasi riwayat\n\n")
+	builder.WriteString(" " + i18n.T("sync.title") + "\n\n")
 	if m.historyMessage != "" {
 		builder.WriteString(m.historyMessage)
 	} else {
-		builder.WriteString("Menunggu data WhatsApp...")
+		builder.WriteString(i18n.T("sync.waiting"))
 	}
 	builder.WriteString("\n\n")
 	builder.WriteString(m.syncProgress.View())
@@ -207,7 +261,8 @@ func (m model) historySyncView() string {
 func (m model) chatLayout(width, height int) string {
 	leftWidth, rightWidth := m.computePaneWidths(width)
 
-	leftPane := leftPaneStyle.Width(leftWidth).Height(height).Render(m.roomList.View())
+	rooms := lipgloss.JoinVertical(lipgloss.Left, m.connectionView(leftWidth-leftPaneStyle.GetHorizontalFrameSize()), m.roomList.View())
+	leftPane := leftPaneStyle.Width(leftWidth).Height(height).Render(rooms)
 	rightPane := rightPaneStyle.Width(rightWidth).Height(height).Render(m.chatPane(rightWidth, height))
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, leftPane, rightPane)
@@ -243,7 +298,7 @@ func (m model) chatPane(width, height int) string {
 			Height(h).
 			AlignHorizontal(lipgloss.Center).
 			AlignVertical(lipgloss.Center).
-			Render("watui")
+			Render(lipgloss.JoinVertical(lipgloss.Center, "watui", "", subtleStyle.Render(i18n.T("rooms.hint"))))
 	}
 
 	timeLabel := "-"
@@ -252,42 +307,130 @@ func (m model) chatPane(width, height int) string {
 	}
 
 	meta := fmt.Sprintf("%s · %s", room.ID, timeLabel)
-	unread := "Tidak ada pesan baru"
+	unread := i18n.T("chat.no_unread")
 	if room.UnreadCount > 0 {
-		unread = fmt.Sprintf("%d pesan belum dibaca", room.UnreadCount)
+		unread = i18n.T("chat.unread", room.UnreadCount)
 	}
 
-	lastMsg := "Belum ada pesan"
-	if room.LastMessage != "" {
-		lastMsg = room.LastMessage
+	// The group panel takes the right side of the pane, next to the
+	// conversation.
+	panelWidth := m.groupPanelWidth(width - rightPaneStyle.GetHorizontalFrameSize())
+	var panel string
+	if panelWidth > 0 {
+		panel = m.groupPanelView(room.ID, panelWidth, height)
+		width -= panelWidth
 	}
 
-	history := m.chatSummaries[room.ID]
-	var historyBuilder strings.Builder
-	if len(history) == 0 {
-		historyBuilder.WriteString("Belum ada riwayat pesan.\n")
-	} else {
-		start := 0
-		if len(history) > 10 {
-			start = len(history) - 10
-		}
-		for _, line := range history[start:] {
-			historyBuilder.WriteString("• " + line + "\n")
-		}
+	title := titleStyle.Render(room.Title)
+	if presence := m.presenceLabel(room.ID); presence != "" {
+		title += "  " + subtleStyle.Render(presence)
 	}
 
-	return lipgloss.JoinVertical(
-		lipgloss.Left,
-		titleStyle.Render(room.Title),
+	header := []string{
+		title,
 		subtleStyle.Render(meta),
 		subtleStyle.Render(unread),
 		"",
-		"Pesan terakhir:",
-		lastMsg,
-		"",
-		"Riwayat terbaru:",
-		historyBuilder.String(),
-	)
+	}
+
+	messagesHeight := height - len(header)
+	var composer string
+	if m.roomList.OpenedRoom() != nil {
+		composer = m.composerView(width)
+		messagesHeight -= lipgloss.Height(composer)
+	}
+	if messagesHeight < 1 {
+		messagesHeight = 1
+	}
+
+	messages := m.messages
+	if messages.ChatID != room.ID {
+		messages = messages.SetChat(room.ID, m.chatMessages[room.ID])
+	}
+	messages = messages.SetSize(width-rightPaneStyle.GetHorizontalFrameSize(), messagesHeight)
+
+	sections := append(header, lipgloss.PlaceVertical(messagesHeight, lipgloss.Bottom, messages.View()))
+	if composer != "" {
+		sections = append(sections, composer)
+	}
+
+	conversation := lipgloss.JoinVertical(lipgloss.Left, sections...)
+	if panel == "" {
+		return conversation
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().Width(width).Render(conversation), panel)
+}
+
+// messagePaneSize mirrors the layout of chatPane so the message viewport can
+// be sized outside of View.
+func (m model) messagePaneSize() (int, int) {
+	innerWidth, innerHeight := m.innerSize()
+	_, rightWidth := m.computePaneWidths(innerWidth)
+
+	const headerHeight = 4
+	width := rightWidth - rightPaneStyle.GetHorizontalFrameSize()
+	width -= m.groupPanelWidth(width)
+	return width, innerHeight - headerHeight - lipgloss.Height(m.composerView(width))
+}
+
+func (m model) composerView(width int) string {
+	composer := m.composer
+	composer.SetWidth(width - rightPaneStyle.GetHorizontalFrameSize())
+
+	hint := i18n.T("composer.hint_typing")
+	if m.mention.suggesting() {
+		hint = i18n.T("composer.hint_mention")
+	}
+	if !composer.Focused() {
+		hint = i18n.T("composer.hint_idle")
+		if isGroup(m.messages.ChatID) {
+			hint += i18n.T("composer.hint_info")
+		}
+		if selected := m.messages.Selected(); selected != nil && selected.ID != "" && !selected.Deleted {
+			hint += i18n.T("composer.hint_react")
+			if selected.Media {
+				hint += i18n.T("composer.hint_media")
+			}
+			if editable(selected) {
+				hint += i18n.T("composer.hint_edit")
+			}
+			if revocable(selected) {
+				hint += i18n.T("composer.hint_revoke")
+			}
+		}
+	}
+
+	var above []string
+	if m.media.saving {
+		above = append(above, m.savePromptView())
+	} else if m.media.notice != "" {
+		above = append(above, subtleStyle.Render(previewLine(m.media.notice, width-4)))
+	}
+	if attachment := m.attachmentView(width); attachment != "" {
+		above = append(above, attachment)
+	}
+	if m.mention.suggesting() {
+		above = append(above, m.mentionSuggestionsView())
+	}
+	if m.react.picking {
+		above = append(above, m.reactionPickerView(width))
+	}
+	if m.revoke.confirming {
+		above = append(above, m.revokeConfirmView(width))
+	}
+	if m.edit.active {
+		above = append(above, subtleStyle.Render(i18n.T("composer.editing")))
+	}
+	if target := m.replyTarget(); target != nil {
+		quote := previewLine(target.Sender+": "+strings.Join(strings.Fields(target.Body), " "), width-4)
+		above = append(above, subtleStyle.Render(i18n.T("composer.replying", quote)))
+	}
+
+	view := composerStyle.Render(composer.View()) + "\n" + subtleStyle.Render(hint)
+	if len(above) == 0 {
+		return view
+	}
+	return strings.Join(above, "\n") + "\n" + view
 }
 
 func (m model) activeRoom() *roomlist.Room {
@@ -363,7 +506,7 @@ func (m model) syncOverlayBox() string {
 
 	label := m.syncOverlay.label
 	if label == "" {
-		label = "Sinkronisasi data"
+		label = i18n.T("sync.data")
 	}
 
 	return syncOverlayStyle.Render(label + "\n" + m.syncProgress.View())
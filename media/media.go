@@ -0,0 +1,233 @@
+// Package media downloads message attachments into a content-addressed
+// cache and hands them to the user's file viewer.
+package media
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+)
+
+var ErrNoAttachment = errors.New("message has no attachment")
+
+// Attachment is the downloadable part of a message.
+type Attachment struct {
+	Kind     string
+	MimeType string
+	FileName string
+	SHA256   []byte
+	Size     uint64
+
+	source whatsmeow.DownloadableMessage
+}
+
+// Downloader fetches and decrypts attachments; *whatsmeow.Client is one.
+type Downloader interface {
+	Download(ctx context.Context, msg whatsmeow.DownloadableMessage) ([]byte, error)
+}
+
+// Find returns the attachment of an image, video, audio, document or
+// sticker message.
+func Find(msg *waE2E.Message) (Attachment, bool) {
+	switch {
+	case msg == nil:
+		return Attachment{}, false
+	case msg.GetImageMessage() != nil:
+		img := msg.GetImageMessage()
+		return Attachment{Kind: "image", MimeType: img.GetMimetype(), SHA256: img.GetFileSHA256(), Size: img.GetFileLength(), source: img}, true
+	case msg.GetVideoMessage() != nil:
+		video := msg.GetVideoMessage()
+		return Attachment{Kind: "video", MimeType: video.GetMimetype(), SHA256: video.GetFileSHA256(), Size: video.GetFileLength(), source: video}, true
+	case msg.GetAudioMessage() != nil:
+		audio := msg.GetAudioMessage()
+		return Attachment{Kind: "audio", MimeType: audio.GetMimetype(), SHA256: audio.GetFileSHA256(), Size: audio.GetFileLength(), source: audio}, true
+	case msg.GetDocumentMessage() != nil:
+		doc := msg.GetDocumentMessage()
+		return Attachment{Kind: "document", MimeType: doc.GetMimetype(), FileName: doc.GetFileName(), SHA256: doc.GetFileSHA256(), Size: doc.GetFileLength(), source: doc}, true
+	case msg.GetStickerMessage() != nil:
+		sticker := msg.GetStickerMessage()
+		return Attachment{Kind: "sticker", MimeType: sticker.GetMimetype(), SHA256: sticker.GetFileSHA256(), Size: sticker.GetFileLength(), source: sticker}, true
+	default:
+		return Attachment{}, false
+	}
+}
+
+// Name is the file name the attachment is saved under by default: the
+// original name of documents, otherwise one derived from the content hash.
+func (a Attachment) Name() string {
+	if name := filepath.Base(strings.TrimSpace(a.FileName)); name != "" && name != "." && name != string(filepath.Separator) {
+		return name
+	}
+
+	hash := hex.EncodeToString(a.SHA256)
+	if len(hash) > 12 {
+		hash = hash[:12]
+	}
+	return fmt.Sprintf("%s-%s%s", a.Kind, hash, a.extension())
+}
+
+func (a Attachment) extension() string {
+	if ext := filepath.Ext(a.FileName); ext != "" {
+		return ext
+	}
+
+	mimeType, _, _ := strings.Cut(a.MimeType, ";")
+	mimeType = strings.TrimSpace(mimeType)
+	switch mimeType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	case "video/mp4":
+		return ".mp4"
+	case "audio/ogg":
+		return ".ogg"
+	case "audio/mpeg":
+		return ".mp3"
+	case "audio/mp4":
+		return ".m4a"
+	case "application/pdf":
+		return ".pdf"
+	}
+	if exts, err := mime.ExtensionsByType(mimeType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
+
+// Cache stores downloaded attachments under the hex SHA-256 of their
+// content, so an attachment forwarded to several chats is kept once.
+type Cache struct {
+	dir string
+}
+
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// Path is where the attachment lives in the cache once fetched.
+func (c *Cache) Path(a Attachment) string {
+	hash := hex.EncodeToString(a.SHA256)
+	return filepath.Join(c.dir, hash[:2], hash+a.extension())
+}
+
+// Fetch returns the cached file of the attachment, downloading it first
+// when it is not cached yet.
+func (c *Cache) Fetch(ctx context.Context, d Downloader, a Attachment) (string, error) {
+	if a.source == nil {
+		return "", ErrNoAttachment
+	}
+	if len(a.SHA256) < 2 {
+		return "", errors.New("attachment has no file hash")
+	}
+
+	path := c.Path(a)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	data, err := d.Download(ctx, a.source)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", err
+	}
+
+	// Write next to the final path and rename, so an interrupted download
+	// never leaves a truncated file under a valid hash.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Save copies a cached file to dst. When dst is a directory the file keeps
+// name inside it. It returns the path written.
+func Save(src, dst, name string) (string, error) {
+	if info, err := os.Stat(dst); (err == nil && info.IsDir()) || strings.HasSuffix(dst, string(filepath.Separator)) {
+		dst = filepath.Join(dst, name)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return "", err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return "", err
+	}
+	return dst, out.Close()
+}
+
+// Open starts command on path without waiting for it. An empty command
+// uses the desktop's default handler. The path replaces a "{}" argument,
+// or is appended when there is none.
+func Open(path, command string) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		args = []string{defaultOpener()}
+	}
+
+	replaced := false
+	for i, arg := range args[1:] {
+		if strings.Contains(arg, "{}") {
+			args[i+1] = strings.ReplaceAll(arg, "{}", path)
+			replaced = true
+		}
+	}
+	if !replaced {
+		args = append(args, path)
+	}
+
+	// The viewer must not draw over the TUI, so its output is dropped.
+	cmd := exec.Command(args[0], args[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+func defaultOpener() string {
+	if runtime.GOOS == "darwin" {
+		return "open"
+	}
+	return "xdg-open"
+}
@@ -0,0 +1,203 @@
+package media
+
+import (
+	"context"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.mau.fi/whatsmeow"
+	waE2E "go.mau.fi/whatsmeow/proto/waE2E"
+	"google.golang.org/protobuf/proto"
+)
+
+// Uploader encrypts and uploads attachments; *whatsmeow.Client is one.
+type Uploader interface {
+	UploadReader(ctx context.Context, plaintext io.Reader, tempFile io.ReadWriteSeeker, appInfo whatsmeow.MediaType) (whatsmeow.UploadResponse, error)
+}
+
+// File is a local file about to be sent.
+type File struct {
+	Path     string
+	Name     string
+	MimeType string
+	Kind     string
+	Size     int64
+}
+
+// Inspect reads what is needed to send path: its type and size. JPEG and PNG
+// go out as photos and common audio formats as audio; everything else is a
+// document.
+func Inspect(path string) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return File{}, err
+	}
+	if !info.Mode().IsRegular() {
+		return File{}, &os.PathError{Op: "send", Path: path, Err: os.ErrInvalid}
+	}
+
+	mimeType := mime.TypeByExtension(strings.ToLower(filepath.Ext(path)))
+	if mimeType == "" {
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(f, head)
+		mimeType = http.DetectContentType(head[:n])
+	}
+
+	return File{
+		Path:     path,
+		Name:     filepath.Base(path),
+		MimeType: mimeType,
+		Kind:     kindOf(mimeType),
+		Size:     info.Size(),
+	}, nil
+}
+
+func kindOf(mimeType string) string {
+	base, _, _ := strings.Cut(mimeType, ";")
+	switch strings.TrimSpace(base) {
+	case "image/jpeg", "image/png":
+		return "image"
+	case "audio/ogg", "audio/mpeg", "audio/mp4", "audio/aac", "audio/amr":
+		return "audio"
+	default:
+		return "document"
+	}
+}
+
+// Upload sends the file to the WhatsApp media servers and returns the message
+// that shares it. Audio messages have no caption. progress, when set, is
+// called as the encrypted file is uploaded.
+func Upload(ctx context.Context, u Uploader, file File, caption string, progress func(sent, total int64)) (*waE2E.Message, error) {
+	in, err := os.Open(file.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp("", "watui-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	mediaType := whatsmeow.MediaDocument
+	switch file.Kind {
+	case "image":
+		mediaType = whatsmeow.MediaImage
+	case "audio":
+		mediaType = whatsmeow.MediaAudio
+	}
+
+	resp, err := u.UploadReader(ctx, in, &progressFile{file: tmp, report: progress}, mediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	var captionPtr *string
+	if caption = strings.TrimSpace(caption); caption != "" {
+		captionPtr = proto.String(caption)
+	}
+
+	switch file.Kind {
+	case "image":
+		img := &waE2E.ImageMessage{
+			URL:           proto.String(resp.URL),
+			DirectPath:    proto.String(resp.DirectPath),
+			MediaKey:      resp.MediaKey,
+			Mimetype:      proto.String(file.MimeType),
+			FileEncSHA256: resp.FileEncSHA256,
+			FileSHA256:    resp.FileSHA256,
+			FileLength:    proto.Uint64(resp.FileLength),
+			Caption:       captionPtr,
+		}
+		if width, height, ok := imageSize(file.Path); ok {
+			img.Width = proto.Uint32(width)
+			img.Height = proto.Uint32(height)
+		}
+		return &waE2E.Message{ImageMessage: img}, nil
+
+	case "audio":
+		return &waE2E.Message{AudioMessage: &waE2E.AudioMessage{
+			URL:           proto.String(resp.URL),
+			DirectPath:    proto.String(resp.DirectPath),
+			MediaKey:      resp.MediaKey,
+			Mimetype:      proto.String(file.MimeType),
+			FileEncSHA256: resp.FileEncSHA256,
+			FileSHA256:    resp.FileSHA256,
+			FileLength:    proto.Uint64(resp.FileLength),
+		}}, nil
+
+	default:
+		return &waE2E.Message{DocumentMessage: &waE2E.DocumentMessage{
+			URL:           proto.String(resp.URL),
+			DirectPath:    proto.String(resp.DirectPath),
+			MediaKey:      resp.MediaKey,
+			Mimetype:      proto.String(file.MimeType),
+			Title:         proto.String(file.Name),
+			FileName:      proto.String(file.Name),
+			FileEncSHA256: resp.FileEncSHA256,
+			FileSHA256:    resp.FileSHA256,
+			FileLength:    proto.Uint64(resp.FileLength),
+			Caption:       captionPtr,
+		}}, nil
+	}
+}
+
+func imageSize(path string) (uint32, uint32, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint32(cfg.Width), uint32(cfg.Height), true
+}
+
+// progressFile is the temporary file whatsmeow encrypts into and then
+// uploads from. Writes size the upload; reads are the upload itself. The
+// file is not embedded, so io.Copy cannot bypass Read through WriteTo.
+type progressFile struct {
+	file   *os.File
+	size   int64
+	sent   int64
+	report func(sent, total int64)
+}
+
+func (f *progressFile) Write(p []byte) (int, error) {
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *progressFile) Read(p []byte) (int, error) {
+	n, err := f.file.Read(p)
+	f.sent += int64(n)
+	if f.report != nil && n > 0 {
+		f.report(f.sent, f.size)
+	}
+	return n, err
+}
+
+func (f *progressFile) Seek(offset int64, whence int) (int64, error) {
+	return f.file.Seek(offset, whence)
+}
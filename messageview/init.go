@@ -0,0 +1,7 @@
+package messageview
+
+import tea "github.com/charmbracelet/bubbletea"
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
@@ -0,0 +1,401 @@
+package messageview
+
+import (
+	"slices"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Status is the delivery state of our own messages, shown as ticks.
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	StatusSent
+	StatusDelivered
+	StatusRead
+	StatusPlayed
+)
+
+type Message struct {
+	ID        string
+	Sender    string
+	SenderJID string
+	Body      string
+	Time      time.Time
+	FromMe    bool
+	Pending   bool
+	Failed    bool
+	Edited    bool
+	Deleted   bool
+	Status    Status
+	Quote     *Quote
+	// Mentioned holds the JIDs of the members the body mentions.
+	Mentioned []string
+	Media     bool
+	// Thumbnail is a small JPEG or PNG of an image or sticker, drawn under
+	// the message when an ImageRenderer is set.
+	Thumbnail []byte
+	Reactions []Reaction
+}
+
+// Reaction counts the participants who reacted to a message with Emoji.
+// Mine is set when we are one of them.
+type Reaction struct {
+	Emoji string
+	Count int
+	Mine  bool
+}
+
+// ImageRenderer draws encoded images as terminal lines at most width cells
+// wide. It returns "" when it cannot, and the message shows only its text.
+type ImageRenderer interface {
+	Render(key string, data []byte, width int) string
+}
+
+// Quote is the message a reply refers to, shown above the reply.
+type Quote struct {
+	ID        string
+	Sender    string
+	SenderJID string
+	Body      string
+}
+
+// LoadOlderMsg is emitted when the user scrolls past the oldest loaded
+// message. Before is the oldest message currently shown.
+type LoadOlderMsg struct {
+	ChatID string
+	Before Message
+}
+
+// LoadNewerMsg is emitted when the user scrolls past the newest loaded
+// message of a window opened away from the end of the conversation. After is
+// the newest message currently shown.
+type LoadNewerMsg struct {
+	ChatID string
+	After  Message
+}
+
+type Model struct {
+	ChatID       string
+	Messages     []Message
+	cursor       int
+	viewStart    int
+	width        int
+	height       int
+	pendingGoTop bool
+	loadingOlder bool
+	exhausted    bool
+	hasNewer     bool
+	loadingNewer bool
+
+	// heights caches the rendered height of each message at the current
+	// width, 0 until measured. The cursor does not change heights.
+	heights []int
+
+	images ImageRenderer
+
+	selectedItemColor lipgloss.AdaptiveColor
+	ownItemColor      lipgloss.AdaptiveColor
+	readColor         lipgloss.AdaptiveColor
+}
+
+func New() Model {
+	return Model{
+		selectedItemColor: lipgloss.AdaptiveColor{Light: "212", Dark: "212"},
+		ownItemColor:      lipgloss.AdaptiveColor{Light: "28", Dark: "114"},
+		readColor:         lipgloss.AdaptiveColor{Light: "33", Dark: "39"},
+	}
+}
+
+// SetImageRenderer enables image previews.
+func (m Model) SetImageRenderer(images ImageRenderer) Model {
+	m.images = images
+	return m
+}
+
+// SetChat replaces the shown conversation and moves the cursor to the
+// newest message.
+func (m Model) SetChat(chatID string, messages []Message) Model {
+	if chatID != m.ChatID {
+		m.exhausted = false
+		m.loadingOlder = false
+	}
+
+	m.ChatID = chatID
+	m.Messages = append([]Message(nil), messages...)
+	m.heights = nil
+	m.cursor = len(m.Messages) - 1
+	m.viewStart = 0
+	m.pendingGoTop = false
+	m.hasNewer = false
+	m.loadingNewer = false
+	m.ensureCursorVisible()
+
+	return m
+}
+
+// SetWindow shows a slice of the conversation with the cursor on the message
+// with selectedID. When hasNewer is set, scrolling past the last message asks
+// for newer ones and live messages are held back until the window reaches the
+// end of the conversation.
+func (m Model) SetWindow(chatID string, messages []Message, selectedID string, hasNewer bool) Model {
+	m = m.SetChat(chatID, messages)
+	m.hasNewer = hasNewer
+
+	for i := range m.Messages {
+		if m.Messages[i].ID == selectedID {
+			m.cursor = i
+			break
+		}
+	}
+	m.viewStart = m.cursor
+	m.ensureCursorVisible()
+
+	return m
+}
+
+func (m Model) SetSize(width, height int) Model {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	if width != m.width {
+		m.heights = nil
+	}
+	m.width = width
+	m.height = height
+	m.ensureCursorVisible()
+	return m
+}
+
+// Append adds msg after the newest message, or replaces the message with the
+// same ID. The cursor follows new messages while it sits on the newest one.
+func (m Model) Append(msg Message) Model {
+	if msg.ID != "" {
+		for i := range m.Messages {
+			if m.Messages[i].ID == msg.ID {
+				m.Messages[i] = msg
+				m.forgetHeight(i)
+				return m
+			}
+		}
+	}
+
+	if m.hasNewer {
+		return m
+	}
+
+	follow := m.cursor >= len(m.Messages)-1
+	m.Messages = append(m.Messages, msg)
+	m.heights = append(m.heights, 0)
+	if follow {
+		m.cursor = len(m.Messages) - 1
+	}
+	m.ensureCursorVisible()
+
+	return m
+}
+
+// Prepend inserts older messages before the oldest one, skipping messages
+// already shown, and keeps the cursor on the same message.
+func (m Model) Prepend(messages []Message) Model {
+	m.loadingOlder = false
+
+	known := make(map[string]bool, len(m.Messages))
+	for _, msg := range m.Messages {
+		if msg.ID != "" {
+			known[msg.ID] = true
+		}
+	}
+
+	var older []Message
+	for _, msg := range messages {
+		if msg.ID != "" && known[msg.ID] {
+			continue
+		}
+		older = append(older, msg)
+	}
+	if len(older) == 0 {
+		return m
+	}
+
+	m.Messages = append(older, m.Messages...)
+	m.heights = append(make([]int, len(older)), m.heights...)
+	m.cursor += len(older)
+	m.viewStart += len(older)
+	m.ensureCursorVisible()
+
+	return m
+}
+
+// AppendNewer adds a page of newer messages after the newest one shown. more
+// reports whether the store holds even newer messages.
+func (m Model) AppendNewer(messages []Message, more bool) Model {
+	m.loadingNewer = false
+	m.hasNewer = more
+
+	known := make(map[string]bool, len(m.Messages))
+	for _, msg := range m.Messages {
+		if msg.ID != "" {
+			known[msg.ID] = true
+		}
+	}
+	for _, msg := range messages {
+		if msg.ID != "" && known[msg.ID] {
+			continue
+		}
+		m.Messages = append(m.Messages, msg)
+		m.heights = append(m.heights, 0)
+	}
+	m.ensureCursorVisible()
+
+	return m
+}
+
+func (m Model) UpdateMessage(id string, fn func(msg *Message)) Model {
+	if id == "" {
+		return m
+	}
+
+	for i := range m.Messages {
+		if m.Messages[i].ID == id {
+			fn(&m.Messages[i])
+			m.forgetHeight(i)
+			break
+		}
+	}
+	m.ensureCursorVisible()
+
+	return m
+}
+
+// UpdateMessages applies fn to every loaded message.
+func (m Model) UpdateMessages(fn func(msg *Message)) Model {
+	for i := range m.Messages {
+		fn(&m.Messages[i])
+	}
+	m.heights = nil
+	m.ensureCursorVisible()
+
+	return m
+}
+
+// StopLoading clears the loading state so scrolling up asks for older
+// messages again.
+func (m Model) StopLoading() Model {
+	m.loadingOlder = false
+	m.loadingNewer = false
+	return m
+}
+
+// SetExhausted marks that no older messages exist for the chat.
+func (m Model) SetExhausted() Model {
+	m.loadingOlder = false
+	m.exhausted = true
+	return m
+}
+
+func (m Model) LoadingOlder() bool {
+	return m.loadingOlder
+}
+
+func (m Model) Selected() *Message {
+	if m.cursor < 0 || m.cursor >= len(m.Messages) {
+		return nil
+	}
+
+	msg := m.Messages[m.cursor]
+	return &msg
+}
+
+func (m Model) Newest() *Message {
+	if len(m.Messages) == 0 {
+		return nil
+	}
+
+	msg := m.Messages[len(m.Messages)-1]
+	return &msg
+}
+
+func (m Model) Oldest() *Message {
+	if len(m.Messages) == 0 {
+		return nil
+	}
+
+	msg := m.Messages[0]
+	return &msg
+}
+
+func (m *Model) ensureCursorVisible() {
+	if len(m.Messages) == 0 {
+		m.cursor = 0
+		m.viewStart = 0
+		return
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if m.cursor >= len(m.Messages) {
+		m.cursor = len(m.Messages) - 1
+	}
+
+	if m.height <= 0 || m.width <= 0 {
+		m.viewStart = 0
+		return
+	}
+
+	if m.viewStart > m.cursor {
+		m.viewStart = m.cursor
+	}
+
+	for m.viewStart < m.cursor && m.heightBetween(m.viewStart, m.cursor) > m.availableHeight() {
+		m.viewStart++
+	}
+
+	for m.viewStart > 0 && m.heightBetween(m.viewStart-1, len(m.Messages)-1) <= m.availableHeight() {
+		m.viewStart--
+	}
+}
+
+func (m *Model) heightBetween(from, to int) int {
+	total := 0
+	for i := from; i <= to && i < len(m.Messages); i++ {
+		total += m.itemHeight(i)
+	}
+	return total
+}
+
+func (m *Model) itemHeight(i int) int {
+	if len(m.heights) != len(m.Messages) {
+		m.heights = make([]int, len(m.Messages))
+	}
+	if m.heights[i] == 0 {
+		m.heights[i] = lipgloss.Height(m.renderItem(i))
+	}
+	return m.heights[i]
+}
+
+// forgetHeight drops the cached height of a changed message. The cache is
+// copied first, as earlier copies of the model share it.
+func (m *Model) forgetHeight(i int) {
+	if i >= len(m.heights) {
+		return
+	}
+	m.heights = slices.Clone(m.heights)
+	m.heights[i] = 0
+}
+
+// availableHeight leaves room for the status line shown above the messages.
+func (m Model) availableHeight() int {
+	if m.statusLine() == "" {
+		return m.height
+	}
+	if m.height <= 1 {
+		return 1
+	}
+	return m.height - 1
+}
@@ -0,0 +1,111 @@
+package messageview
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		key := msg.String()
+		switch key {
+		case "j", "down":
+			if m.cursor < len(m.Messages)-1 {
+				m.cursor++
+			} else {
+				cmd = m.requestNewer()
+			}
+			m.pendingGoTop = false
+
+		case "k", "up":
+			if m.cursor > 0 {
+				m.cursor--
+			} else {
+				cmd = m.requestOlder()
+			}
+			m.pendingGoTop = false
+
+		case "pgdown":
+			if m.cursor == len(m.Messages)-1 {
+				cmd = m.requestNewer()
+			}
+			m.cursor += m.pageSize()
+			if m.cursor > len(m.Messages)-1 {
+				m.cursor = len(m.Messages) - 1
+			}
+			m.pendingGoTop = false
+
+		case "pgup":
+			if m.cursor == 0 {
+				cmd = m.requestOlder()
+			}
+			m.cursor -= m.pageSize()
+			if m.cursor < 0 {
+				m.cursor = 0
+			}
+			m.pendingGoTop = false
+
+		case "g":
+			if m.pendingGoTop {
+				m.cursor = 0
+				m.pendingGoTop = false
+			} else {
+				m.pendingGoTop = true
+			}
+
+		case "G":
+			m.cursor = len(m.Messages) - 1
+			m.pendingGoTop = false
+
+		default:
+			m.pendingGoTop = false
+		}
+	}
+
+	m.ensureCursorVisible()
+
+	return m, cmd
+}
+
+func (m *Model) requestOlder() tea.Cmd {
+	if m.loadingOlder || m.exhausted || m.ChatID == "" {
+		return nil
+	}
+
+	m.loadingOlder = true
+	load := LoadOlderMsg{ChatID: m.ChatID}
+	if oldest := m.Oldest(); oldest != nil {
+		load.Before = *oldest
+	}
+
+	return func() tea.Msg {
+		return load
+	}
+}
+
+func (m *Model) requestNewer() tea.Cmd {
+	if m.loadingNewer || !m.hasNewer || m.ChatID == "" {
+		return nil
+	}
+
+	m.loadingNewer = true
+	load := LoadNewerMsg{ChatID: m.ChatID}
+	if newest := m.Newest(); newest != nil {
+		load.After = *newest
+	}
+
+	return func() tea.Msg {
+		return load
+	}
+}
+
+func (m Model) pageSize() int {
+	// Messages usually take more than one line, so a page is a rough guess.
+	size := m.height / 2
+	if size < 1 {
+		size = 1
+	}
+	return size
+}
@@ -0,0 +1,173 @@
+package messageview
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/9d4/watui/i18n"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const pendingMarker = " 🕓"
+
+func (m Model) View() string {
+	if len(m.Messages) == 0 {
+		if status := m.statusLine(); status != "" {
+			return lipgloss.NewStyle().Faint(true).Render(status)
+		}
+		return lipgloss.NewStyle().Faint(true).Render(i18n.T("messages.empty"))
+	}
+
+	var lines []string
+	if status := m.statusLine(); status != "" {
+		lines = append(lines, lipgloss.NewStyle().Faint(true).Render(status))
+	}
+
+	available := m.availableHeight()
+	used := 0
+	for i := m.viewStart; i < len(m.Messages); i++ {
+		item := strings.Split(m.renderItem(i), "\n")
+		if m.height > 0 && used+len(item) > available {
+			item = item[:available-used]
+		}
+		lines = append(lines, item...)
+		used += len(item)
+		if m.height > 0 && used >= available {
+			break
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) statusLine() string {
+	switch {
+	case m.loadingOlder:
+		return i18n.T("messages.loading")
+	case m.exhausted:
+		return i18n.T("messages.start")
+	default:
+		return ""
+	}
+}
+
+func (m Model) renderItem(i int) string {
+	msg := m.Messages[i]
+
+	timeLabel := "-"
+	if !msg.Time.IsZero() {
+		timeLabel = msg.Time.Format("02 Jan 15:04")
+	}
+
+	sender := msg.Sender
+	if sender == "" {
+		sender = i18n.T("sender.unknown")
+	}
+
+	body := msg.Body
+	switch {
+	case msg.Deleted:
+		body = lipgloss.NewStyle().Faint(true).Italic(true).Render("🚫 " + i18n.T("messages.deleted"))
+	case body == "":
+		body = "-"
+	case msg.Edited:
+		body += lipgloss.NewStyle().Faint(true).Render(" (" + i18n.T("messages.edited") + ")")
+	}
+
+	switch {
+	case msg.Failed:
+		body += " ⚠ " + i18n.T("messages.failed")
+	case msg.Pending:
+		body += pendingMarker
+	case msg.FromMe:
+		body += m.ticks(msg.Status)
+	}
+
+	header := lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("[%s]", timeLabel))
+	senderStyle := lipgloss.NewStyle().Bold(true)
+	if msg.FromMe {
+		senderStyle = senderStyle.Foreground(m.ownItemColor)
+	}
+
+	line := header + " " + senderStyle.Render(sender) + ": " + body
+	if msg.Quote != nil {
+		line = m.renderQuote(*msg.Quote) + "\n" + line
+	}
+	if preview := m.renderPreview(msg); preview != "" {
+		line += "\n" + preview
+	}
+	if len(msg.Reactions) > 0 {
+		line += "\n" + m.renderReactions(msg.Reactions)
+	}
+
+	// The selected message gets a left bar; others are padded by the same
+	// amount so wrapping does not change when the cursor moves.
+	style := lipgloss.NewStyle().PaddingLeft(1)
+	if m.width > 1 {
+		style = style.Width(m.width)
+	}
+	if i == m.cursor {
+		style = lipgloss.NewStyle().
+			BorderStyle(lipgloss.ThickBorder()).
+			BorderLeft(true).
+			BorderForeground(m.selectedItemColor)
+		if m.width > 1 {
+			style = style.Width(m.width - 1)
+		}
+	}
+
+	return style.Render(line)
+}
+
+func (m Model) renderPreview(msg Message) string {
+	if m.images == nil || len(msg.Thumbnail) == 0 || m.width < 8 {
+		return ""
+	}
+	return m.images.Render(msg.ID, msg.Thumbnail, m.width-2)
+}
+
+// renderQuote flattens the quoted message to one faint line so long quotes
+// do not push the reply off screen.
+func (m Model) renderQuote(quote Quote) string {
+	sender := quote.Sender
+	if sender == "" {
+		sender = i18n.T("sender.unknown")
+	}
+
+	text := sender + ": " + strings.Join(strings.Fields(quote.Body), " ")
+	if limit := m.width - 4; limit > 1 {
+		if runes := []rune(text); len(runes) > limit {
+			text = string(runes[:limit-1]) + "…"
+		}
+	}
+
+	return lipgloss.NewStyle().Faint(true).Render("╭ " + text)
+}
+
+// renderReactions lists the reactions under a message, ours highlighted.
+func (m Model) renderReactions(reactions []Reaction) string {
+	chips := make([]string, 0, len(reactions))
+	for _, r := range reactions {
+		chip := fmt.Sprintf("%s %d", r.Emoji, r.Count)
+		if r.Mine {
+			chip = lipgloss.NewStyle().Foreground(m.ownItemColor).Render(chip)
+		} else {
+			chip = lipgloss.NewStyle().Faint(true).Render(chip)
+		}
+		chips = append(chips, chip)
+	}
+	return "  " + strings.Join(chips, "  ")
+}
+
+func (m Model) ticks(status Status) string {
+	switch status {
+	case StatusSent:
+		return lipgloss.NewStyle().Faint(true).Render(" ✓")
+	case StatusDelivered:
+		return lipgloss.NewStyle().Faint(true).Render(" ✓✓")
+	case StatusRead, StatusPlayed:
+		return lipgloss.NewStyle().Foreground(m.readColor).Render(" ✓✓")
+	default:
+		return ""
+	}
+}
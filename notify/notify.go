@@ -0,0 +1,196 @@
+// Package notify tells the user about incoming messages through the
+// terminal bell, terminal notifications (OSC 9 and OSC 777) or the desktop
+// notification service.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Method int
+
+const (
+	MethodOff Method = iota
+	MethodBell
+	MethodOSC9
+	MethodOSC777
+	MethodDBus
+)
+
+// At most burstSize notifications are shown per burstWindow; the rest of a
+// burst is dropped.
+const (
+	burstSize   = 3
+	burstWindow = 10 * time.Second
+)
+
+const commandTimeout = 5 * time.Second
+
+// ParseMethod reads the notify setting. "auto" and "" detect a method from
+// the environment.
+func ParseMethod(name string) (Method, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		return Detect(), nil
+	case "bell":
+		return MethodBell, nil
+	case "osc9":
+		return MethodOSC9, nil
+	case "osc777":
+		return MethodOSC777, nil
+	case "dbus", "desktop":
+		return MethodDBus, nil
+	case "off", "none":
+		return MethodOff, nil
+	default:
+		return MethodOff, fmt.Errorf("unknown notify method %q", name)
+	}
+}
+
+// Detect prefers desktop notifications when a session bus and a client for
+// it are around, then the notification escape of terminals known to
+// support one, then the bell.
+func Detect() Method {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("DBUS_SESSION_BUS_ADDRESS") != "" && dbusClient() != "":
+		return MethodDBus
+	case program == "iTerm.app", program == "WezTerm", program == "ghostty", os.Getenv("KITTY_WINDOW_ID") != "":
+		return MethodOSC9
+	case strings.HasPrefix(term, "foot"), strings.HasPrefix(term, "rxvt-unicode"):
+		return MethodOSC777
+	default:
+		return MethodBell
+	}
+}
+
+type Notifier struct {
+	method Method
+	out    io.Writer
+
+	mu   sync.Mutex
+	sent []time.Time
+}
+
+// New returns a notifier that writes terminal notifications to out.
+func New(method Method, out io.Writer) *Notifier {
+	return &Notifier{method: method, out: out}
+}
+
+// Terminal opens the controlling terminal for terminal notifications and
+// other escapes written outside the views. The TUI renders to stdout from
+// another goroutine, so writing there could land inside a frame. Without a
+// terminal it falls back to stderr.
+func Terminal() io.Writer {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return os.Stderr
+	}
+	return tty
+}
+
+func (n *Notifier) Enabled() bool {
+	return n != nil && n.method != MethodOff
+}
+
+// Notify shows a notification unless the current burst is used up.
+func (n *Notifier) Notify(title, body string) error {
+	if !n.Enabled() || !n.allow(time.Now()) {
+		return nil
+	}
+
+	switch n.method {
+	case MethodOSC9:
+		return n.write(fmt.Sprintf("\x1b]9;%s\x07", sanitize(title+": "+body)))
+	case MethodOSC777:
+		title = strings.ReplaceAll(sanitize(title), ";", ",")
+		return n.write(fmt.Sprintf("\x1b]777;notify;%s;%s\x07", title, sanitize(body)))
+	case MethodDBus:
+		return sendDBus(title, body)
+	default:
+		return n.write("\a")
+	}
+}
+
+func (n *Notifier) allow(now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	recent := n.sent[:0]
+	for _, at := range n.sent {
+		if now.Sub(at) < burstWindow {
+			recent = append(recent, at)
+		}
+	}
+	n.sent = recent
+
+	if len(n.sent) >= burstSize {
+		return false
+	}
+	n.sent = append(n.sent, now)
+	return true
+}
+
+func (n *Notifier) write(seq string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	_, err := io.WriteString(n.out, seq)
+	return err
+}
+
+// sanitize keeps message text from ending the escape sequence early. C1
+// controls go too: U+009C is a string terminator to some terminals.
+func sanitize(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			return ' '
+		}
+		return r
+	}, text)
+}
+
+func dbusClient() string {
+	for _, name := range []string{"notify-send", "gdbus"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// sendDBus calls org.freedesktop.Notifications through notify-send, or
+// gdbus when notify-send is not installed.
+func sendDBus(title, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	if path, err := exec.LookPath("notify-send"); err == nil {
+		return exec.CommandContext(ctx, path, "--app-name=watui", "--", title, body).Run()
+	}
+
+	path, err := exec.LookPath("gdbus")
+	if err != nil {
+		return err
+	}
+	return exec.CommandContext(ctx, path, "call", "--session",
+		"--dest", "org.freedesktop.Notifications",
+		"--object-path", "/org/freedesktop/Notifications",
+		"--method", "org.freedesktop.Notifications.Notify",
+		"watui", "0", "", gvariantString(title), gvariantString(body), "[]", "{}", "-1",
+	).Run()
+}
+
+// gvariantString quotes text as a GVariant string literal for gdbus.
+func gvariantString(text string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(text) + "'"
+}
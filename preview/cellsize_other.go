@@ -0,0 +1,7 @@
+//go:build !unix
+
+package preview
+
+func cellSize() (int, int) {
+	return defaultCellWidth, defaultCellHeight
+}
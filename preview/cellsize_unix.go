@@ -0,0 +1,19 @@
+//go:build unix
+
+package preview
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cellSize returns the pixel size of a terminal cell, falling back to a
+// common 10×20 when the terminal does not report its pixel size.
+func cellSize() (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 || ws.Xpixel == 0 || ws.Ypixel == 0 {
+		return defaultCellWidth, defaultCellHeight
+	}
+	return int(ws.Xpixel / ws.Col), int(ws.Ypixel / ws.Row)
+}
@@ -0,0 +1,66 @@
+package preview
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// halfBlocks draws two pixels per cell with "▀": the top one as foreground
+// and the bottom one as background color.
+func halfBlocks(img image.Image, width int, trueColor bool) string {
+	// A cell is about twice as high as it is wide, and holds two pixels.
+	cols, rows := fit(img, width, 1, 2)
+	if cols == 0 {
+		return ""
+	}
+	px := scale(img, cols, rows*2)
+
+	var b strings.Builder
+	for y := 0; y < rows; y++ {
+		if y > 0 {
+			b.WriteByte('\n')
+		}
+		for x := 0; x < cols; x++ {
+			b.WriteString(sgr(38, px.RGBAAt(x, y*2), trueColor))
+			b.WriteString(sgr(48, px.RGBAAt(x, y*2+1), trueColor))
+			b.WriteString("▀")
+		}
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// sgr selects a foreground (38) or background (48) color.
+func sgr(layer int, c color.RGBA, trueColor bool) string {
+	if trueColor {
+		return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", layer, c.R, c.G, c.B)
+	}
+	return fmt.Sprintf("\x1b[%d;5;%dm", layer, ansi256(c))
+}
+
+// ansi256 maps a color to the 6×6×6 cube of the 256 color palette, or to
+// its gray ramp when the color is close to gray.
+func ansi256(c color.RGBA) int {
+	r, g, b := int(c.R), int(c.G), int(c.B)
+	if max(r, g, b)-min(r, g, b) < 10 {
+		gray := (r + g + b) / 3
+		switch {
+		case gray < 8:
+			return 16
+		case gray > 238:
+			return 231
+		default:
+			return 232 + (gray-8)*24/231
+		}
+	}
+
+	level := func(v int) int {
+		if v < 48 {
+			return 0
+		}
+		return min((v-35)/40, 5)
+	}
+	return 16 + 36*level(r) + 6*level(g) + level(b)
+}
@@ -0,0 +1,102 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+)
+
+const (
+	kittyPlaceholder = "\U0010EEEE"
+	kittyChunkSize   = 4096
+)
+
+// kittyDiacritics encode row and column numbers of placeholder cells, in
+// the order of kitty's rowcolumn-diacritics table. MaxRows entries are
+// enough: columns after the first are inferred from the previous cell.
+var kittyDiacritics = []rune{
+	0x0305, 0x030D, 0x030E, 0x0310, 0x0312, 0x033D,
+	0x033E, 0x033F, 0x0346, 0x034A, 0x034B, 0x034C,
+}
+
+// kitty transmits the image once per size and shows it through unicode
+// placeholders, which are ordinary cells to the TUI layout: kitty draws the
+// image wherever they end up, so redraws and scrolling keep it in place
+// without sending it again.
+func (r *Renderer) kitty(key, cacheKey string, img image.Image, width int) string {
+	cols, rows := fit(img, width, defaultCellWidth, defaultCellHeight)
+	if r.terminal == nil || cols == 0 || rows > len(kittyDiacritics) {
+		return ""
+	}
+
+	id := r.kittyID(key)
+	if r.placed[id] != cacheKey {
+		var encoded bytes.Buffer
+		if err := png.Encode(&encoded, img); err != nil {
+			return ""
+		}
+		// One write, so it cannot land inside a frame drawn meanwhile.
+		if _, err := io.WriteString(r.terminal, kittyTransmit(id, cols, rows, encoded.Bytes())); err != nil {
+			return ""
+		}
+		r.placed[id] = cacheKey
+	}
+
+	color := fmt.Sprintf("\x1b[38;5;%dm", id)
+	lines := make([]string, rows)
+	for y := range lines {
+		var b strings.Builder
+		b.WriteString(color)
+		b.WriteString(kittyPlaceholder)
+		b.WriteRune(kittyDiacritics[y])
+		b.WriteRune(kittyDiacritics[0])
+		b.WriteString(strings.Repeat(kittyPlaceholder, cols-1))
+		b.WriteString("\x1b[39m")
+		lines[y] = b.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// kittyID keeps ids stable per key. The id is also the 256 color
+// foreground of the placeholders, so it stays within 1..255.
+func (r *Renderer) kittyID(key string) uint32 {
+	if id, ok := r.ids[key]; ok {
+		return id
+	}
+
+	r.nextID = r.nextID%255 + 1
+	for k, id := range r.ids {
+		if id == r.nextID {
+			delete(r.ids, k)
+		}
+	}
+	delete(r.placed, r.nextID)
+	r.ids[key] = r.nextID
+	return r.nextID
+}
+
+// kittyTransmit sends a PNG and creates a virtual placement of cols×rows
+// cells for the placeholders to show.
+func kittyTransmit(id uint32, cols, rows int, data []byte) string {
+	payload := base64.StdEncoding.EncodeToString(data)
+
+	var b strings.Builder
+	for i := 0; i < len(payload); i += kittyChunkSize {
+		end := min(i+kittyChunkSize, len(payload))
+		more := 0
+		if end < len(payload) {
+			more = 1
+		}
+
+		if i == 0 {
+			fmt.Fprintf(&b, "\x1b_Ga=T,U=1,f=100,q=2,i=%d,c=%d,r=%d,m=%d;%s\x1b\\", id, cols, rows, more, payload[i:end])
+		} else {
+			fmt.Fprintf(&b, "\x1b_Gm=%d;%s\x1b\\", more, payload[i:end])
+		}
+	}
+	return b.String()
+}
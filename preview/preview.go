@@ -0,0 +1,160 @@
+// Package preview draws images inside the terminal with kitty graphics,
+// sixel or colored half blocks, whichever the terminal supports.
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+type Protocol int
+
+const (
+	ProtocolText Protocol = iota
+	ProtocolHalfBlocks
+	ProtocolSixel
+	ProtocolKitty
+)
+
+// MaxRows caps the height of a preview so one image does not fill the pane.
+const MaxRows = 12
+
+const maxCached = 256
+
+const defaultCellWidth, defaultCellHeight = 10, 20
+
+// ParseProtocol reads the image_preview setting. "auto" and "" detect the
+// protocol from the environment.
+func ParseProtocol(name string) (Protocol, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "auto":
+		return Detect(), nil
+	case "kitty":
+		return ProtocolKitty, nil
+	case "sixel":
+		return ProtocolSixel, nil
+	case "halfblocks", "blocks":
+		return ProtocolHalfBlocks, nil
+	case "text", "off", "none":
+		return ProtocolText, nil
+	default:
+		return ProtocolText, fmt.Errorf("unknown image preview %q", name)
+	}
+}
+
+// Detect guesses the best protocol from the variables terminals set. Only
+// kitty and ghostty support the unicode placeholders the kitty renderer
+// needs; terminals known for sixel get sixel; any color terminal can draw
+// half blocks.
+func Detect() Protocol {
+	term := os.Getenv("TERM")
+	program := os.Getenv("TERM_PROGRAM")
+
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "", term == "xterm-kitty", term == "xterm-ghostty", program == "ghostty":
+		return ProtocolKitty
+	case program == "WezTerm", program == "iTerm.app", strings.HasPrefix(term, "foot"),
+		strings.HasPrefix(term, "mlterm"), strings.HasPrefix(term, "contour"), strings.Contains(term, "sixel"):
+		return ProtocolSixel
+	case term == "" || term == "dumb" || term == "linux":
+		return ProtocolText
+	default:
+		return ProtocolHalfBlocks
+	}
+}
+
+// Renderer turns encoded images into lines of terminal output and caches
+// the result, since views are redrawn on every update.
+type Renderer struct {
+	protocol  Protocol
+	trueColor bool
+	cache     map[string]string
+
+	// kitty refers to transmitted images by id. Images go to terminal
+	// once, outside the rendered views, and placed remembers the cache
+	// key each id was sent for.
+	ids      map[string]uint32
+	nextID   uint32
+	placed   map[uint32]string
+	terminal io.Writer
+}
+
+// New returns a renderer for protocol. kitty images are transmitted to
+// terminal, which must reach the same terminal as the views.
+func New(protocol Protocol, terminal io.Writer) *Renderer {
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+	return &Renderer{
+		protocol:  protocol,
+		trueColor: colorTerm == "truecolor" || colorTerm == "24bit",
+		cache:     make(map[string]string),
+		ids:       make(map[string]uint32),
+		placed:    make(map[uint32]string),
+		terminal:  terminal,
+	}
+}
+
+func (r *Renderer) Protocol() Protocol {
+	return r.protocol
+}
+
+// Render draws data, a JPEG or PNG, at most width cells wide and MaxRows
+// rows high. key identifies the image across redraws. It returns "" when
+// the image cannot be drawn, so callers fall back to text.
+func (r *Renderer) Render(key string, data []byte, width int) string {
+	if r == nil || r.protocol == ProtocolText || len(data) == 0 || width < 4 {
+		return ""
+	}
+
+	cacheKey := fmt.Sprintf("%s/%d", key, width)
+	if out, ok := r.cache[cacheKey]; ok && (out == "" || r.protocol != ProtocolKitty || r.placed[r.kittyID(key)] == cacheKey) {
+		return out
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		r.remember(cacheKey, "")
+		return ""
+	}
+
+	var out string
+	switch r.protocol {
+	case ProtocolKitty:
+		out = r.kitty(key, cacheKey, img, width)
+	case ProtocolSixel:
+		out = sixel(img, width)
+	default:
+		out = halfBlocks(img, width, r.trueColor)
+	}
+	r.remember(cacheKey, out)
+	return out
+}
+
+func (r *Renderer) remember(key, out string) {
+	if len(r.cache) >= maxCached {
+		clear(r.cache)
+	}
+	r.cache[key] = out
+}
+
+// fit returns the size in cells that keeps the aspect ratio of img, given
+// the pixel size of a cell.
+func fit(img image.Image, width, cellWidth, cellHeight int) (cols, rows int) {
+	b := img.Bounds()
+	if b.Dx() == 0 || b.Dy() == 0 {
+		return 0, 0
+	}
+
+	cols = width
+	rows = (cols*cellWidth*b.Dy() + b.Dx()*cellHeight - 1) / (b.Dx() * cellHeight)
+	if rows > MaxRows {
+		rows = MaxRows
+		cols = rows * cellHeight * b.Dx() / (b.Dy() * cellWidth)
+	}
+	return max(cols, 1), max(rows, 1)
+}
@@ -0,0 +1,111 @@
+package preview
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"strings"
+)
+
+// sixel draws the image as sixel graphics over a block of blank cells.
+//
+// The graphics are sent from the end of the last row, after moving the
+// cursor back to the top left of the block: rows are painted top to bottom,
+// so drawing earlier would let the blank rows below erase the image.
+func sixel(img image.Image, width int) string {
+	cellWidth, cellHeight := cellSize()
+	cols, rows := fit(img, width, cellWidth, cellHeight)
+	if cols == 0 {
+		return ""
+	}
+
+	px := scale(img, cols*cellWidth, rows*cellHeight)
+	quantized := image.NewPaletted(px.Bounds(), palette.Plan9)
+	draw.FloydSteinberg.Draw(quantized, px.Bounds(), px, image.Point{})
+
+	blank := strings.Repeat(" ", cols)
+	lines := make([]string, rows)
+	for i := range lines {
+		lines[i] = blank
+	}
+
+	var b strings.Builder
+	b.WriteString(blank)
+	b.WriteString("\x1b7")
+	if rows > 1 {
+		fmt.Fprintf(&b, "\x1b[%dA", rows-1)
+	}
+	fmt.Fprintf(&b, "\x1b[%dD", cols)
+	b.WriteString(encodeSixel(quantized))
+	b.WriteString("\x1b8")
+	lines[rows-1] = b.String()
+
+	return strings.Join(lines, "\n")
+}
+
+func encodeSixel(img *image.Paletted) string {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+
+	for i, c := range img.Palette {
+		r, g, bl, _ := c.RGBA()
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+	}
+
+	row := make([]byte, w)
+	for top := 0; top < h; top += 6 {
+		used := make(map[uint8]bool)
+		for y := top; y < min(top+6, h); y++ {
+			for x := 0; x < w; x++ {
+				used[img.ColorIndexAt(bounds.Min.X+x, bounds.Min.Y+y)] = true
+			}
+		}
+
+		first := true
+		for idx := range len(img.Palette) {
+			if !used[uint8(idx)] {
+				continue
+			}
+			for x := 0; x < w; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < h; dy++ {
+					if img.ColorIndexAt(bounds.Min.X+x, bounds.Min.Y+top+dy) == uint8(idx) {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+			}
+
+			if !first {
+				b.WriteByte('$')
+			}
+			first = false
+			fmt.Fprintf(&b, "#%d", idx)
+			writeRuns(&b, row)
+		}
+		b.WriteByte('-')
+	}
+
+	b.WriteString("\x1b\\")
+	return b.String()
+}
+
+// writeRuns writes sixel data with repeated characters run-length encoded.
+func writeRuns(b *strings.Builder, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if n := j - i; n > 3 {
+			fmt.Fprintf(b, "!%d%c", n, row[i])
+		} else {
+			b.Write(row[i:j])
+		}
+		i = j
+	}
+}
@@ -0,0 +1,194 @@
+package roomlist
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/9d4/watui/i18n"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// row is a room shown in the list. Title and preview hold the rune positions
+// matched by the filter.
+type row struct {
+	index   int
+	title   []int
+	preview []int
+}
+
+func newFilterInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "/ "
+	ti.Placeholder = i18n.T("rooms.filter_placeholder")
+	return ti
+}
+
+// Filtering reports whether the filter input has focus and wants every key.
+func (m Model) Filtering() bool {
+	return m.filtering
+}
+
+func (m Model) Filter() string {
+	return m.filterInput.Value()
+}
+
+func (m Model) startFilter() (Model, tea.Cmd) {
+	m.filtering = true
+	m.pendingGoTop = false
+	return m, m.filterInput.Focus()
+}
+
+func (m Model) clearFilter() Model {
+	cursorID := m.cursorID()
+	m.filtering = false
+	m.filterInput.Blur()
+	m.filterInput.Reset()
+	m.refilter(cursorID)
+	return m
+}
+
+func (m Model) updateFilter(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		return m.clearFilter(), nil
+
+	case "enter":
+		m.filtering = false
+		m.filterInput.Blur()
+		m.openCursor()
+		return m, nil
+
+	case "up", "ctrl+p":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+		m.ensureCursorVisible()
+		return m, nil
+
+	case "down", "ctrl+n":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+		m.ensureCursorVisible()
+		return m, nil
+	}
+
+	cursorID := m.cursorID()
+	before := m.filterInput.Value()
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	if m.filterInput.Value() != before {
+		m.refilter(cursorID)
+	}
+
+	return m, cmd
+}
+
+// refilter rebuilds the shown rows and keeps the cursor on the room with
+// cursorID while it still matches. Archived rooms only show in their own
+// section, or when the filter of the main list matches them.
+func (m *Model) refilter(cursorID string) {
+	pattern := []rune(strings.ToLower(strings.TrimSpace(m.filterInput.Value())))
+
+	m.rows = m.rows[:0:0]
+	for i, room := range m.Rooms {
+		if room.Archived != m.showArchived && (len(pattern) == 0 || m.showArchived) {
+			continue
+		}
+		if len(pattern) == 0 {
+			m.rows = append(m.rows, row{index: i})
+			continue
+		}
+
+		title, titleOK := fuzzyMatch(room.Title, pattern)
+		preview, previewOK := fuzzyMatch(flatten(room.LastMessage), pattern)
+		_, idOK := fuzzyMatch(room.ID, pattern)
+		if !titleOK && !previewOK && !idOK {
+			continue
+		}
+		m.rows = append(m.rows, row{index: i, title: title, preview: preview})
+	}
+
+	if cursorID != "" {
+		for i, r := range m.rows {
+			if m.Rooms[r.index].ID == cursorID {
+				m.cursor = i
+				break
+			}
+		}
+	}
+
+	m.ensureCursorVisible()
+}
+
+// fuzzyMatch reports whether pattern appears in text as a case-insensitive
+// subsequence and returns the matched rune positions. pattern must be
+// lower case.
+func fuzzyMatch(text string, pattern []rune) ([]int, bool) {
+	if len(pattern) == 0 {
+		return nil, true
+	}
+
+	var positions []int
+	next := 0
+	for i, r := range []rune(text) {
+		if unicode.ToLower(r) != pattern[next] {
+			continue
+		}
+		positions = append(positions, i)
+		next++
+		if next == len(pattern) {
+			return positions, true
+		}
+	}
+
+	return nil, false
+}
+
+// highlight renders text with base, marking the runes at positions.
+func (m Model) highlight(text string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(text)
+	}
+
+	marked := base.Faint(false).Underline(true).Foreground(m.matchColor)
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p] = true
+	}
+
+	var (
+		b    strings.Builder
+		part []rune
+		on   bool
+	)
+	flush := func() {
+		if len(part) == 0 {
+			return
+		}
+		if on {
+			b.WriteString(marked.Render(string(part)))
+		} else {
+			b.WriteString(base.Render(string(part)))
+		}
+		part = part[:0]
+	}
+
+	for i, r := range []rune(text) {
+		if matched[i] != on {
+			flush()
+			on = matched[i]
+		}
+		part = append(part, r)
+	}
+	flush()
+
+	return b.String()
+}
+
+func flatten(msg string) string {
+	return strings.TrimSpace(strings.ReplaceAll(msg, "\n", " "))
+}
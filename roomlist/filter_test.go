@@ -0,0 +1,97 @@
+package roomlist
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	for _, tc := range []struct {
+		text    string
+		pattern string
+		want    []int
+		ok      bool
+	}{
+		{"Budi Santoso", "", nil, true},
+		{"Budi Santoso", "bs", []int{0, 5}, true},
+		{"Budi Santoso", "BS", nil, false}, // patterns come in lower case
+		{"BUDI", "budi", []int{0, 1, 2, 3}, true},
+		{"Budi Santoso", "sb", nil, false},
+		{"Budi", "budii", nil, false},
+		{"banana", "aa", []int{1, 3}, true},
+		{"Ökö Grüße", "ögß", []int{0, 4, 7}, true},
+		{"😀 Budi", "bd", []int{2, 4}, true},
+		{"Keluarga 👨‍👩‍👧 Besar", "kb", []int{0, 15}, true},
+		{"", "a", nil, false},
+	} {
+		got, ok := fuzzyMatch(tc.text, []rune(tc.pattern))
+		if ok != tc.ok || !slices.Equal(got, tc.want) {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, %v; want %v, %v", tc.text, tc.pattern, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	m := New()
+	base := lipgloss.NewStyle()
+	marked := base.Faint(false).Underline(true).Foreground(m.matchColor)
+
+	for _, tc := range []struct {
+		text      string
+		positions []int
+		want      string
+	}{
+		{"Budi", nil, base.Render("Budi")},
+		{"Budi", []int{0, 2}, marked.Render("B") + base.Render("u") + marked.Render("d") + base.Render("i")},
+		{"Budi", []int{1, 2}, base.Render("B") + marked.Render("ud") + base.Render("i")},
+		{"Ökö 😀 Ana", []int{1, 6}, base.Render("Ö") + marked.Render("k") + base.Render("ö 😀 ") + marked.Render("A") + base.Render("na")},
+	} {
+		if got := m.highlight(tc.text, tc.positions, base); got != tc.want {
+			t.Errorf("highlight(%q, %v) = %q, want %q", tc.text, tc.positions, got, tc.want)
+		}
+	}
+}
+
+func TestVisiblePositions(t *testing.T) {
+	if got := visiblePositions([]int{0, 3, 9, 10}, 9); !slices.Equal(got, []int{0, 3}) {
+		t.Errorf("visiblePositions = %v, want [0 3]", got)
+	}
+}
+
+func TestRefilterMatchesTitlePreviewAndID(t *testing.T) {
+	m := New()
+	m.Rooms = []Room{
+		{ID: "111@s.whatsapp.net", Title: "Budi", LastMessage: "see you\nat 5"},
+		{ID: "222@s.whatsapp.net", Title: "Ani", LastMessage: "ok"},
+		{ID: "333@g.us", Title: "Keluarga", LastMessage: "", Archived: true},
+	}
+
+	for _, tc := range []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{"111@s.whatsapp.net", "222@s.whatsapp.net"}},
+		{"bd", []string{"111@s.whatsapp.net"}},
+		{"u a", []string{"111@s.whatsapp.net"}},
+		{"222", []string{"222@s.whatsapp.net"}},
+		{"klg", []string{"333@g.us"}},
+	} {
+		m.filterInput.SetValue(tc.filter)
+		m.refilter("")
+
+		var got []string
+		for _, r := range m.rows {
+			got = append(got, m.Rooms[r.index].ID)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("filter %q shows %v, want %v", tc.filter, got, tc.want)
+		}
+	}
+}
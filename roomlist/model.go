@@ -5,6 +5,7 @@ import (
 	"slices"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -14,19 +15,40 @@ type Room struct {
 	LastMessage string
 	Time        time.Time
 	UnreadCount int
+	Pending     bool
+
+	// Pinned, Archived and MutedUntil follow the chat's app state.
+	Pinned     bool
+	Archived   bool
+	MutedUntil time.Time
+}
+
+// Muted reports whether notifications of the room are silenced right now.
+func (r Room) Muted() bool {
+	return time.Now().Before(r.MutedUntil)
 }
 
 type Model struct {
-	Rooms           []Room
+	Rooms []Room
+
+	// rows are the rooms passing the filter, in list order. cursor indexes
+	// rows while openedRoomIndex indexes Rooms.
+	rows            []row
 	cursor          int
 	openedRoomIndex *int
 	viewStart       int
 	viewportHeight  int
 	pendingGoTop    bool
+	filtering       bool
+	filterInput     textinput.Model
+
+	// showArchived lists the archived rooms instead of the others.
+	showArchived bool
 
 	selectedItemColor lipgloss.AdaptiveColor
 	inactiveItemColor lipgloss.AdaptiveColor
 	openedItemColor   lipgloss.AdaptiveColor
+	matchColor        lipgloss.AdaptiveColor
 }
 
 func New() Model {
@@ -34,6 +56,8 @@ func New() Model {
 		selectedItemColor: lipgloss.AdaptiveColor{Dark: fmt.Sprintf("%d", 0b010101), Light: "212"},
 		inactiveItemColor: lipgloss.AdaptiveColor{Light: "243", Dark: "243"},
 		openedItemColor:   lipgloss.AdaptiveColor{Light: "86", Dark: "86"},
+		matchColor:        lipgloss.AdaptiveColor{Light: "166", Dark: "214"},
+		filterInput:       newFilterInput(),
 	}
 }
 
@@ -51,25 +75,55 @@ func (m Model) OpenedRoom() *Room {
 	return &room
 }
 
-func (m Model) CursorRoom() *Room {
-	if len(m.Rooms) == 0 {
-		return nil
+// OpenRoom moves the cursor to the room with jid and opens it, clearing a
+// filter that hides the room. Unknown rooms leave the model unchanged.
+func (m Model) OpenRoom(jid string) Model {
+	idx := m.indexOf(jid)
+	if idx < 0 {
+		return m
 	}
 
-	if m.cursor < 0 {
-		return nil
+	if m.rowOf(jid) < 0 {
+		m = m.clearFilter()
+	}
+	if m.rowOf(jid) < 0 {
+		m.showArchived = m.Rooms[idx].Archived
+		m.refilter(jid)
 	}
-	if m.cursor >= len(m.Rooms) {
+	m.cursor = m.rowOf(jid)
+	m.openedRoomIndex = &idx
+	m.pendingGoTop = false
+	m.ensureCursorVisible()
+
+	return m
+}
+
+func (m Model) CursorRoom() *Room {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
 		return nil
 	}
 
-	room := m.Rooms[m.cursor]
+	room := m.Rooms[m.rows[m.cursor].index]
 	return &room
 }
 
+// ReplaceRooms sorts rooms newest first, pinned rooms before the others, and
+// keeps the cursor and the opened room on the same rooms when they are still
+// present.
 func (m Model) ReplaceRooms(rooms []Room) Model {
+	cursorID := m.cursorID()
+	var openedID string
+	if room := m.OpenedRoom(); room != nil {
+		openedID = room.ID
+	}
+
 	slices.SortFunc(rooms, func(a, b Room) int {
 		switch {
+		case a.Pinned != b.Pinned:
+			if a.Pinned {
+				return -1
+			}
+			return 1
 		case a.Time.After(b.Time):
 			return -1
 		case a.Time.Before(b.Time):
@@ -80,87 +134,113 @@ func (m Model) ReplaceRooms(rooms []Room) Model {
 	})
 
 	m.Rooms = rooms
-	if len(m.Rooms) == 0 {
-		m.cursor = 0
-		m.openedRoomIndex = nil
-		return m
+	m.openedRoomIndex = nil
+	if idx := m.indexOf(openedID); idx >= 0 {
+		m.openedRoomIndex = &idx
 	}
 
-	if m.cursor >= len(m.Rooms) {
-		m.cursor = len(m.Rooms) - 1
-	}
-
-	if m.openedRoomIndex != nil && *m.openedRoomIndex >= len(m.Rooms) {
-		m.openedRoomIndex = nil
-	}
-
-	m.ensureCursorVisible()
+	m.refilter(cursorID)
 
 	return m
 }
 
 func (m Model) UpsertRoom(room Room) Model {
-	found := false
-	var openedJID string
-	var openedHas bool
-	if m.openedRoomIndex != nil && *m.openedRoomIndex < len(m.Rooms) {
-		openedJID = m.Rooms[*m.openedRoomIndex].ID
-		openedHas = true
+	if idx := m.indexOf(room.ID); idx >= 0 {
+		m.Rooms[idx] = room
+	} else {
+		m.Rooms = append(m.Rooms, room)
 	}
 
-	cursorJID := ""
-	if len(m.Rooms) > 0 && m.cursor < len(m.Rooms) {
-		cursorJID = m.Rooms[m.cursor].ID
+	return m.ReplaceRooms(m.Rooms)
+}
+
+// Room returns the room with jid, or nil when the list does not have it.
+func (m Model) Room(jid string) *Room {
+	idx := m.indexOf(jid)
+	if idx < 0 {
+		return nil
 	}
 
-	for i := range m.Rooms {
-		if m.Rooms[i].ID == room.ID {
-			m.Rooms[i] = room
-			found = true
-			break
-		}
+	room := m.Rooms[idx]
+	return &room
+}
+
+func (m Model) SetUnread(jid string, count int) Model {
+	if idx := m.indexOf(jid); idx >= 0 {
+		m.Rooms[idx].UnreadCount = count
 	}
 
-	if !found {
-		m.Rooms = append(m.Rooms, room)
+	return m
+}
+
+func (m Model) SetPinned(jid string, pinned bool) Model {
+	if idx := m.indexOf(jid); idx >= 0 {
+		m.Rooms[idx].Pinned = pinned
+		return m.ReplaceRooms(m.Rooms)
 	}
 
-	m = m.ReplaceRooms(m.Rooms)
+	return m
+}
 
-	if cursorJID != "" {
-		for i, r := range m.Rooms {
-			if r.ID == cursorJID {
-				m.cursor = i
-				break
-			}
+// SetArchived moves a room in or out of the archived section. Archived rooms
+// are never pinned.
+func (m Model) SetArchived(jid string, archived bool) Model {
+	if idx := m.indexOf(jid); idx >= 0 {
+		m.Rooms[idx].Archived = archived
+		if archived {
+			m.Rooms[idx].Pinned = false
 		}
+		return m.ReplaceRooms(m.Rooms)
 	}
 
-	if openedHas {
-		for i, r := range m.Rooms {
-			if r.ID == openedJID {
-				idx := i
-				m.openedRoomIndex = &idx
-				break
-			}
-		}
+	return m
+}
+
+func (m Model) SetMutedUntil(jid string, until time.Time) Model {
+	if idx := m.indexOf(jid); idx >= 0 {
+		m.Rooms[idx].MutedUntil = until
 	}
 
-	m.ensureCursorVisible()
+	return m
+}
+
+// ShowingArchived reports whether the list shows the archived rooms.
+func (m Model) ShowingArchived() bool {
+	return m.showArchived
+}
 
+func (m Model) toggleArchived() Model {
+	m.showArchived = !m.showArchived
+	m.cursor = 0
+	m.viewStart = 0
+	m.refilter("")
 	return m
 }
 
+func (m Model) archivedCount() int {
+	n := 0
+	for _, room := range m.Rooms {
+		if room.Archived {
+			n++
+		}
+	}
+	return n
+}
+
+// sectionLine reports whether the line leading to or out of the archived
+// rooms is shown above the list.
+func (m Model) sectionLine() bool {
+	return m.showArchived || m.archivedCount() > 0
+}
+
 func (m Model) UpdateTitle(jid, title string) Model {
 	if title == "" {
 		return m
 	}
 
-	for i := range m.Rooms {
-		if m.Rooms[i].ID == jid {
-			m.Rooms[i].Title = title
-			break
-		}
+	if idx := m.indexOf(jid); idx >= 0 {
+		m.Rooms[idx].Title = title
+		m.refilter(m.cursorID())
 	}
 
 	return m
@@ -175,8 +255,62 @@ func (m Model) SetViewportHeight(h int) Model {
 	return m
 }
 
+func (m Model) indexOf(jid string) int {
+	if jid == "" {
+		return -1
+	}
+	for i := range m.Rooms {
+		if m.Rooms[i].ID == jid {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m Model) rowOf(jid string) int {
+	for i, r := range m.rows {
+		if m.Rooms[r.index].ID == jid {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m Model) cursorID() string {
+	if room := m.CursorRoom(); room != nil {
+		return room.ID
+	}
+	return ""
+}
+
+func (m *Model) openCursor() {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return
+	}
+
+	idx := m.rows[m.cursor].index
+	m.openedRoomIndex = &idx
+}
+
+// listHeight is the number of rooms that fit below the section and filter
+// lines.
+func (m Model) listHeight() int {
+	if m.viewportHeight <= 0 {
+		return m.viewportHeight
+	}
+
+	height := m.viewportHeight
+	if m.sectionLine() {
+		height--
+	}
+	if m.filtering || m.Filter() != "" {
+		height--
+	}
+	return max(height, 1)
+}
+
 func (m *Model) ensureCursorVisible() {
-	if len(m.Rooms) == 0 {
+	if len(m.rows) == 0 {
 		m.cursor = 0
 		m.viewStart = 0
 		return
@@ -184,11 +318,12 @@ func (m *Model) ensureCursorVisible() {
 	if m.cursor < 0 {
 		m.cursor = 0
 	}
-	if m.cursor >= len(m.Rooms) {
-		m.cursor = len(m.Rooms) - 1
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
 	}
 
-	if m.viewportHeight <= 0 {
+	height := m.listHeight()
+	if height <= 0 {
 		m.viewStart = 0
 		return
 	}
@@ -197,13 +332,13 @@ func (m *Model) ensureCursorVisible() {
 		m.viewStart = m.cursor
 	}
 
-	maxStart := len(m.Rooms) - m.viewportHeight
+	maxStart := len(m.rows) - height
 	if maxStart < 0 {
 		maxStart = 0
 	}
 
-	if m.cursor >= m.viewStart+m.viewportHeight {
-		m.viewStart = m.cursor - m.viewportHeight + 1
+	if m.cursor >= m.viewStart+height {
+		m.viewStart = m.cursor - height + 1
 	}
 
 	if m.viewStart > maxStart {
@@ -211,15 +346,16 @@ func (m *Model) ensureCursorVisible() {
 	}
 }
 
-func (m Model) visibleRooms() (start int, rooms []Room) {
-	if m.viewportHeight <= 0 || len(m.Rooms) <= m.viewportHeight {
-		return 0, m.Rooms
+func (m Model) visibleRows() (start int, rows []row) {
+	height := m.listHeight()
+	if height <= 0 || len(m.rows) <= height {
+		return 0, m.rows
 	}
 
-	end := m.viewStart + m.viewportHeight
-	if end > len(m.Rooms) {
-		end = len(m.Rooms)
+	end := m.viewStart + height
+	if end > len(m.rows) {
+		end = len(m.rows)
 	}
 
-	return m.viewStart, m.Rooms[m.viewStart:end]
+	return m.viewStart, m.rows[m.viewStart:end]
 }
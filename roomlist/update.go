@@ -7,10 +7,14 @@ import (
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+
 		key := msg.String()
 		switch key {
 		case "j", "down":
-			if len(m.Rooms)-1 != m.cursor {
+			if m.cursor < len(m.rows)-1 {
 				m.cursor++
 			}
 			m.pendingGoTop = false
@@ -22,11 +26,7 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			m.pendingGoTop = false
 
 		case "enter":
-			if m.openedRoomIndex == nil {
-				m.openedRoomIndex = new(int)
-			}
-
-			*m.openedRoomIndex = m.cursor
+			m.openCursor()
 			m.pendingGoTop = false
 
 		case "g":
@@ -38,20 +38,42 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			}
 
 		case "G":
-			if len(m.Rooms) > 0 {
-				m.cursor = len(m.Rooms) - 1
+			if len(m.rows) > 0 {
+				m.cursor = len(m.rows) - 1
 			} else {
 				m.cursor = 0
 			}
 			m.pendingGoTop = false
 
+		case "/", "ctrl+f":
+			if m.openedRoomIndex == nil {
+				return m.startFilter()
+			}
+
+		case "A":
+			if m.openedRoomIndex == nil && (m.showArchived || m.archivedCount() > 0) {
+				m = m.toggleArchived()
+			}
+
 		default:
 			m.pendingGoTop = false
 
 		case "esc":
+			if m.openedRoomIndex == nil && m.Filter() != "" {
+				m = m.clearFilter()
+			} else if m.openedRoomIndex == nil && m.showArchived {
+				m = m.toggleArchived()
+			}
 			m.openedRoomIndex = nil
 			m.pendingGoTop = false
 		}
+
+	default:
+		if m.filtering {
+			var cmd tea.Cmd
+			m.filterInput, cmd = m.filterInput.Update(msg)
+			return m, cmd
+		}
 	}
 
 	m.ensureCursorVisible()
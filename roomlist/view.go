@@ -3,6 +3,7 @@ package roomlist
 import (
 	"strings"
 
+	"github.com/9d4/watui/i18n"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mattn/go-runewidth"
 )
@@ -10,18 +11,46 @@ import (
 func (m Model) View() string {
 	var roomList strings.Builder
 
-	start, visible := m.visibleRooms()
+	if m.sectionLine() {
+		roomList.WriteString(m.sectionView() + "\n")
+	}
+
+	if m.filtering || m.Filter() != "" {
+		roomList.WriteString(m.filterInput.View() + "\n")
+		if len(m.rows) == 0 {
+			roomList.WriteString(lipgloss.NewStyle().Faint(true).Render(i18n.T("rooms.no_match")) + "\n")
+		}
+	} else if m.showArchived && len(m.rows) == 0 {
+		roomList.WriteString(lipgloss.NewStyle().Faint(true).Render(i18n.T("rooms.archived_empty")) + "\n")
+	}
 
-	for idx, item := range visible {
+	start, visible := m.visibleRows()
+
+	for idx, r := range visible {
 		i := start + idx
+		item := m.Rooms[r.index]
 		timeStr := "-"
 		if !item.Time.IsZero() {
 			timeStr = item.Time.Format("02/01 15:04")
 		}
-		lastMessage := previewText(item.LastMessage, 48)
+
+		previewWidth := 48
+		prefix := "  "
+		if item.Pending {
+			previewWidth = 46
+			prefix += "🕓 "
+		}
+		title := item.Title + roomMarkers(item)
+		lastMessage := previewText(item.LastMessage, previewWidth)
+		previewMatches := r.preview
+		if lastMessage != flatten(item.LastMessage) {
+			// Drop matches hidden by truncation, including the rune replaced
+			// by the ellipsis.
+			previewMatches = visiblePositions(r.preview, len([]rune(lastMessage))-1)
+		}
 
 		switch {
-		case m.openedRoomIndex != nil && *m.openedRoomIndex == i:
+		case m.openedRoomIndex != nil && *m.openedRoomIndex == r.index:
 			roomList.WriteString(
 				lipgloss.NewStyle().
 					Bold(true).
@@ -29,10 +58,9 @@ func (m Model) View() string {
 			)
 
 			roomList.WriteString(
-				lipgloss.NewStyle().
+				m.highlight(title, r.title, lipgloss.NewStyle().
 					Foreground(m.openedItemColor).
-					Bold(true).
-					Render(item.Title) + "\n",
+					Bold(true)) + "\n",
 			)
 
 		case i == m.cursor:
@@ -43,10 +71,9 @@ func (m Model) View() string {
 			)
 
 			roomList.WriteString(
-				lipgloss.NewStyle().
+				m.highlight(title, r.title, lipgloss.NewStyle().
 					Foreground(m.selectedItemColor).
-					Bold(true).
-					Render(item.Title) + "\n",
+					Bold(true)) + "\n",
 			)
 
 		default:
@@ -57,29 +84,56 @@ func (m Model) View() string {
 			)
 
 			roomList.WriteString(
-				lipgloss.NewStyle().
-					Faint(true).
-					Render(item.Title) + "\n",
+				m.highlight(title, r.title, m.dimmed(item)) + "\n",
 			)
 		}
 
+		faint := m.dimmed(item)
 		roomList.WriteString(
-			lipgloss.NewStyle().
-				Faint(true).
-				Render("  "+lastMessage) + "\n\n",
+			faint.Render(prefix) + m.highlight(lastMessage, previewMatches, faint) + "\n\n",
 		)
 	}
 
 	return roomList.String()
 }
 
+// sectionView leads to the archived rooms, or back out of them.
+func (m Model) sectionView() string {
+	faint := lipgloss.NewStyle().Faint(true)
+	if m.showArchived {
+		return lipgloss.NewStyle().Bold(true).Render(i18n.T("rooms.archived_title")) +
+			faint.Render(" · "+i18n.T("rooms.archived_back"))
+	}
+	return faint.Render(i18n.T("rooms.archived", m.archivedCount()) + " · " + i18n.T("rooms.archived_open"))
+}
+
+// dimmed is the style of a room that is not selected. Muted rooms fade
+// further.
+func (m Model) dimmed(room Room) lipgloss.Style {
+	style := lipgloss.NewStyle().Faint(true)
+	if room.Muted() {
+		style = style.Foreground(m.inactiveItemColor)
+	}
+	return style
+}
+
+func roomMarkers(room Room) string {
+	var markers string
+	if room.Pinned {
+		markers += " 📌"
+	}
+	if room.Muted() {
+		markers += " 🔇"
+	}
+	return markers
+}
+
 func previewText(msg string, width int) string {
 	if msg == "" {
 		return "-"
 	}
 
-	msg = strings.ReplaceAll(msg, "\n", " ")
-	msg = strings.TrimSpace(msg)
+	msg = flatten(msg)
 	if msg == "" {
 		return "-"
 	}
@@ -94,3 +148,13 @@ func previewText(msg string, width int) string {
 
 	return runewidth.Truncate(msg, width-1, "") + "…"
 }
+
+func visiblePositions(positions []int, limit int) []int {
+	var visible []int
+	for _, p := range positions {
+		if p < limit {
+			visible = append(visible, p)
+		}
+	}
+	return visible
+}
@@ -2,13 +2,18 @@ package wa
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"os"
 
+	"github.com/mattn/go-sqlite3"
 	"github.com/rs/zerolog"
+	"go.mau.fi/util/dbutil"
+	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/store/sqlstore"
+	"go.mau.fi/whatsmeow/types"
 	waLog "go.mau.fi/whatsmeow/util/log"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
 type Manager struct {
@@ -18,18 +23,52 @@ type Manager struct {
 }
 
 func (m *Manager) SessionCounts() (int, error) {
-	d, err := m.C.GetAllDevices(context.Background())
+	d, err := m.Devices(context.Background())
 	return len(d), err
 }
 
-func NewManager(logger zerolog.Logger) *Manager {
+// Devices lists every paired account stored in the container.
+func (m *Manager) Devices(ctx context.Context) ([]*store.Device, error) {
+	return m.C.GetAllDevices(ctx)
+}
+
+// Device returns the stored device for jid, or nil if it is not paired.
+func (m *Manager) Device(ctx context.Context, jid types.JID) (*store.Device, error) {
+	return m.C.GetDevice(ctx, jid)
+}
+
+// NewDevice creates an unpaired device that is saved once pairing succeeds.
+func (m *Manager) NewDevice() *store.Device {
+	return m.C.NewDevice()
+}
+
+var (
+	ErrSessionLocked   = errors.New("session database is locked")
+	ErrSessionReadOnly = errors.New("session database is read-only")
+	ErrSessionCorrupt  = errors.New("session database is corrupt")
+	ErrSessionTooNew   = errors.New("session database was created by a newer version")
+	ErrSessionOpen     = errors.New("cannot open session database")
+	ErrSessionUpgrade  = errors.New("cannot upgrade session database")
+)
+
+func NewManager(logger zerolog.Logger, dbPath string) (*Manager, error) {
 	dbLog := waLog.Zerolog(logger.With().Str("log", "db").Logger())
 	waLog := waLog.Zerolog(logger.With().Str("log", "wa").Logger())
 
 	ctx := context.Background()
-	container, err := sqlstore.New(ctx, "sqlite3", "file:watui.db?_foreign_keys=on", dbLog)
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?_foreign_keys=on")
 	if err != nil {
-		panic(err)
+		return nil, classifyError(ErrSessionOpen, err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, classifyError(ErrSessionOpen, err)
+	}
+
+	container := sqlstore.NewWithDB(db, "sqlite3", dbLog)
+	if err := container.Upgrade(ctx); err != nil {
+		db.Close()
+		return nil, classifyError(ErrSessionUpgrade, err)
 	}
 
 	m := &Manager{
@@ -37,7 +76,36 @@ func NewManager(logger zerolog.Logger) *Manager {
 		waLog: waLog,
 		C:     container,
 	}
-	return m
+	return m, nil
+}
+
+// classifyError wraps err with the sentinel that best explains it, falling
+// back to stage when the cause is not recognised.
+func classifyError(stage, err error) error {
+	if errors.Is(err, dbutil.ErrUnsupportedDatabaseVersion) {
+		return fmt.Errorf("%w: %w", ErrSessionTooNew, err)
+	}
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code {
+		case sqlite3.ErrBusy, sqlite3.ErrLocked:
+			return fmt.Errorf("%w: %w", ErrSessionLocked, err)
+		case sqlite3.ErrReadonly, sqlite3.ErrPerm, sqlite3.ErrCantOpen:
+			return fmt.Errorf("%w: %w", ErrSessionReadOnly, err)
+		case sqlite3.ErrCorrupt, sqlite3.ErrNotADB:
+			return fmt.Errorf("%w: %w", ErrSessionCorrupt, err)
+		}
+	}
+
+	return fmt.Errorf("%w: %w", stage, err)
+}
+
+func (m *Manager) Close() error {
+	if m == nil || m.C == nil {
+		return nil
+	}
+	return m.C.Close()
 }
 
 func (m *Manager) WaLog() waLog.Logger {